@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spy16/fabric"
@@ -13,18 +17,47 @@ import (
 )
 
 var (
-	db       = flag.String("store", ":memory:", "Storage location")
+	db       = flag.String("store", ":memory:", "Storage location (':memory:', 'disk:<dir>' or sqlite file path)")
 	httpAddr = flag.String("http", ":8080", "HTTP Server Address")
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: fabric [flags] [serve | import | export]\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	fab := fabric.New(setupStore(*db))
+	store := setupStore(*db)
+	fab := fabric.New(store)
+
+	var err error
+	switch cmd := flag.Arg(0); cmd {
+	case "", "serve":
+		mux := server.NewHTTP(fab)
+		log.Printf("starting HTTP API server on '%s'...", *httpAddr)
+		log.Fatalf("server exiting: %v", http.ListenAndServe(*httpAddr, mux))
+
+	case "import":
+		err = runImport(fab, flag.Args()[1:])
+
+	case "export":
+		err = runExport(fab, flag.Args()[1:])
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if c, ok := store.(io.Closer); ok {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
 
-	mux := server.NewHTTP(fab)
-	log.Printf("starting HTTP API server on '%s'...", *httpAddr)
-	log.Fatalf("server exiting: %v", http.ListenAndServe(*httpAddr, mux))
+	if err != nil {
+		log.Fatalf("%s failed: %v", flag.Arg(0), err)
+	}
 }
 
 func setupStore(path string) fabric.Store {
@@ -32,7 +65,15 @@ func setupStore(path string) fabric.Store {
 		return &fabric.InMemoryStore{}
 	}
 
-	db, err := sql.Open("sqlite3", path)
+	if strings.HasPrefix(path, "disk:") {
+		store, err := fabric.OpenDiskStore(strings.TrimPrefix(path, "disk:"), fabric.DiskOptions{})
+		if err != nil {
+			log.Fatalf("failed to open disk store: %v", err)
+		}
+		return store
+	}
+
+	db, err := sql.Open(sqliteDriver, path)
 	if err != nil {
 		log.Fatalf("failed to open db: %v\n", err)
 	}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spy16/fabric"
+)
+
+// runImport implements the 'import' command which loads N-Triples or Turtle
+// files (or stdin) into the store.
+func runImport(fab *fabric.Fabric, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "Input format ('nt' or 'ttl'). Inferred from the file extension if not set")
+	base := fs.String("base", "", "Base IRI of the names (defaults to '"+fabric.DefaultBase+"')")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fabric [flags] import [flags] [file ...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	opts := fabric.RDFOptions{Base: *base}
+	for _, file := range files {
+		f, err := rdfFormat(*format, file)
+		if err != nil {
+			return err
+		}
+
+		if err := importFile(fab, file, f, opts); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+	}
+
+	return nil
+}
+
+func importFile(fab *fabric.Fabric, file, format string, opts fabric.RDFOptions) error {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		fh, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		r = fh
+	}
+
+	ctx := context.Background()
+
+	var res fabric.BatchResult
+	var err error
+	if format == "ttl" {
+		res, err = fab.ImportTurtle(ctx, r, opts)
+	} else {
+		res, err = fab.ImportNTriples(ctx, r, opts)
+	}
+
+	for _, be := range res.Failed {
+		log.Printf("%s: statement %d: %v", file, be.Index, be.Err)
+	}
+	log.Printf("%s: imported %d triples (%d failed)", file, res.Inserted, len(res.Failed))
+	return err
+}
+
+// runExport implements the 'export' command which writes all the triples as
+// N-Triples or Turtle to a file (or stdout).
+func runExport(fab *fabric.Fabric, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "Output format ('nt' or 'ttl'). Inferred from the output file extension if not set")
+	base := fs.String("base", "", "Base IRI of the names (defaults to '"+fabric.DefaultBase+"')")
+	out := fs.String("o", "-", "Output file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fabric [flags] export [flags]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	f, err := rdfFormat(*format, *out)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		fh, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		w = fh
+	}
+
+	ctx := context.Background()
+	opts := fabric.RDFOptions{Base: *base}
+	if f == "ttl" {
+		return fab.ExportTurtle(ctx, w, fabric.Query{}, opts)
+	}
+	return fab.ExportNTriples(ctx, w, fabric.Query{}, opts)
+}
+
+// rdfFormat returns the format to be used for the file.
+func rdfFormat(format, file string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+		if format == "" {
+			format = "nt"
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "nt", "ntriples", "n-triples":
+		return "nt", nil
+
+	case "ttl", "turtle":
+		return "ttl", nil
+	}
+
+	return "", fmt.Errorf("unknown format '%s' (must be 'nt' or 'ttl')", format)
+}
@@ -0,0 +1,23 @@
+//go:build cgo
+// +build cgo
+
+package main
+
+import (
+	"database/sql"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/spy16/fabric"
+)
+
+// sqliteDriver is the sqlite driver with the REGEXP function registered for
+// supporting regex clauses.
+const sqliteDriver = "sqlite3_fabric"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", fabric.MatchRegexp, true)
+		},
+	})
+}
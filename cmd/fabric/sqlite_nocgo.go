@@ -0,0 +1,8 @@
+//go:build !cgo
+// +build !cgo
+
+package main
+
+// sqliteDriver is the stub driver registered by go-sqlite3 when cgo is not
+// available.
+const sqliteDriver = "sqlite3"
@@ -0,0 +1,31 @@
+package fabric_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spy16/fabric"
+	"github.com/spy16/fabric/storetest"
+)
+
+func TestInMemoryStore_Conformance(t *testing.T) {
+	t.Parallel()
+
+	storetest.Run(t, func(t *testing.T) fabric.Store {
+		return &fabric.InMemoryStore{}
+	})
+}
+
+func TestDiskStore_Conformance(t *testing.T) {
+	t.Parallel()
+
+	storetest.Run(t, func(t *testing.T) fabric.Store {
+		dir := tempDir(t)
+		store := openDiskStore(t, dir, fabric.DiskOptions{Sync: fabric.SyncNever})
+		t.Cleanup(func() {
+			store.Close()
+			os.RemoveAll(dir)
+		})
+		return store
+	})
+}
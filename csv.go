@@ -0,0 +1,348 @@
+package fabric
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVOptions configures the mapping between the columns of CSV (or TSV)
+// records and triples.
+type CSVOptions struct {
+	// Comma is the field delimiter. Defaults to ','. Use '\t' for TSV.
+	Comma rune
+
+	// Header is true if the first record is a header naming the columns.
+	Header bool
+
+	// SourceColumn, PredicateColumn, TargetColumn and WeightColumn select
+	// the columns by header name or 0 based index. By default, the columns
+	// are read in that order (without the predicate column if Predicate is
+	// set). The weight column is optional unless it is set explicitly and
+	// can be disabled using '-'.
+	SourceColumn    string
+	PredicateColumn string
+	TargetColumn    string
+	WeightColumn    string
+
+	// Predicate is the predicate of the triples for edge lists without a
+	// predicate column. If PredicateColumn is also set, Predicate is used for
+	// records with an empty predicate.
+	Predicate string
+}
+
+// ImportCSV reads CSV records from r and inserts them into the fabric in
+// batches. Records that could not be mapped onto valid triples or inserted
+// are reported in the result with their index (0 based, excluding header)
+// and a LineError. Returns error if the input cannot be read or the options
+// are not valid.
+func (f *Fabric) ImportCSV(ctx context.Context, r io.Reader, opts CSVOptions) (BatchResult, error) {
+	return f.importTriples(ctx, NewCSVReader(r, opts))
+}
+
+// ExportCSV writes the triples matching the query to w as CSV records.
+func (f *Fabric) ExportCSV(ctx context.Context, w io.Writer, query Query, opts CSVOptions) error {
+	return f.exportTriples(ctx, NewCSVWriter(w, opts), query)
+}
+
+// CSVReader reads triples from CSV records.
+type CSVReader struct {
+	r       *csv.Reader
+	lr      *lineReader
+	opts    CSVOptions
+	cols    csvColumns
+	started bool
+	line    int
+}
+
+// csvColumns holds the indices of the source, predicate, target and weight
+// columns. -1 means the column is not present.
+type csvColumns struct {
+	source, predicate, target, weight int
+	weightRequired                    bool
+}
+
+// NewCSVReader returns a reader for the CSV records in r.
+func NewCSVReader(r io.Reader, opts CSVOptions) *CSVReader {
+	lr := &lineReader{r: bufio.NewReader(r)}
+	cr := csv.NewReader(lr)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.LazyQuotes = cr.Comma == '\t'
+
+	return &CSVReader{r: cr, lr: lr, opts: opts}
+}
+
+// Read returns the triple for the next record. Records that cannot be mapped
+// onto a valid triple are reported using a LineError and reading can be
+// continued. Returns io.EOF when there are no more records.
+func (cr *CSVReader) Read() (Triple, error) {
+	if !cr.started {
+		cr.started = true
+		if err := cr.readHeader(); err != nil {
+			return Triple{}, err
+		}
+	}
+
+	record, err := cr.r.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			cr.line = pe.StartLine
+			return Triple{}, &LineError{Line: pe.StartLine, Err: pe.Err}
+		}
+		return Triple{}, err
+	}
+
+	// the record starts on the line that is as many lines before the last
+	// line read as there are line breaks in the (quoted) fields.
+	cr.line = cr.lr.last()
+	for _, field := range record {
+		cr.line -= strings.Count(field, "\n")
+	}
+
+	tri, err := cr.cols.triple(record, cr.opts.Predicate)
+	if err == nil {
+		err = tri.Validate()
+	}
+
+	if err != nil {
+		return Triple{}, &LineError{Line: cr.line, Err: err}
+	}
+	return tri, nil
+}
+
+// Line returns the line number of the last record read.
+func (cr *CSVReader) Line() int {
+	return cr.line
+}
+
+func (cr *CSVReader) readHeader() error {
+	var header []string
+	if cr.opts.Header {
+		var err error
+		if header, err = cr.r.Read(); err != nil {
+			if err == io.EOF {
+				return err
+			}
+			return fmt.Errorf("failed to read header: %v", err)
+		}
+	}
+
+	cols, err := resolveCSVColumns(cr.opts, header)
+	if err != nil {
+		return err
+	}
+	cr.cols = cols
+	return nil
+}
+
+func resolveCSVColumns(opts CSVOptions, header []string) (csvColumns, error) {
+	cols := csvColumns{source: 0, predicate: 1, target: 2, weight: 3}
+	if opts.Predicate != "" && opts.PredicateColumn == "" {
+		cols = csvColumns{source: 0, predicate: -1, target: 1, weight: 2}
+	}
+
+	refs := []struct {
+		ref string
+		idx *int
+	}{
+		{opts.SourceColumn, &cols.source},
+		{opts.PredicateColumn, &cols.predicate},
+		{opts.TargetColumn, &cols.target},
+		{opts.WeightColumn, &cols.weight},
+	}
+
+	for _, c := range refs {
+		if c.ref == "" {
+			continue
+		}
+
+		idx, err := csvColumn(c.ref, header)
+		if err != nil {
+			return cols, err
+		}
+		*c.idx = idx
+	}
+
+	cols.weightRequired = opts.WeightColumn != "" && cols.weight >= 0
+	if cols.source < 0 || cols.target < 0 || (cols.predicate < 0 && opts.Predicate == "") {
+		return cols, errors.New("source, predicate and target columns are required")
+	}
+
+	return cols, nil
+}
+
+// csvColumn returns the index of the column referred to by name or index.
+func csvColumn(ref string, header []string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "-" {
+		return -1, nil
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), ref) {
+			return i, nil
+		}
+	}
+
+	if idx, err := strconv.Atoi(ref); err == nil && idx >= 0 {
+		return idx, nil
+	}
+
+	return -1, fmt.Errorf("unknown column '%s'", ref)
+}
+
+func (cols csvColumns) triple(record []string, predicate string) (Triple, error) {
+	field := func(idx int) (string, bool) {
+		if idx < 0 || idx >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[idx]), true
+	}
+
+	var tri Triple
+	var found bool
+	if tri.Source, found = field(cols.source); !found {
+		return tri, errors.New("missing source column")
+	}
+
+	if tri.Target, found = field(cols.target); !found {
+		return tri, errors.New("missing target column")
+	}
+
+	if tri.Predicate, found = field(cols.predicate); !found && cols.predicate >= 0 {
+		return tri, errors.New("missing predicate column")
+	}
+	if tri.Predicate == "" {
+		tri.Predicate = predicate
+	}
+
+	weight, found := field(cols.weight)
+	if !found && cols.weightRequired {
+		return tri, errors.New("missing weight column")
+	}
+
+	if weight != "" {
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return tri, fmt.Errorf("weight must be a number, got '%s'", weight)
+		}
+		tri.Weight = w
+	}
+
+	return tri, nil
+}
+
+// CSVWriter writes triples as CSV records with the source, predicate (unless
+// CSVOptions.Predicate is set), target and weight columns. Writes are
+// buffered and Flush must be called after the last triple.
+type CSVWriter struct {
+	w       *csv.Writer
+	opts    CSVOptions
+	started bool
+}
+
+// NewCSVWriter returns a writer writing CSV records to w.
+func NewCSVWriter(w io.Writer, opts CSVOptions) *CSVWriter {
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+
+	return &CSVWriter{w: cw, opts: opts}
+}
+
+// Write writes the triple as a CSV record.
+func (cw *CSVWriter) Write(tri Triple) error {
+	if err := cw.header(); err != nil {
+		return err
+	}
+
+	return cw.w.Write(cw.record(tri.Source, tri.Predicate, tri.Target, strconv.FormatFloat(tri.Weight, 'f', -1, 64)))
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (cw *CSVWriter) Flush() error {
+	if err := cw.header(); err != nil {
+		return err
+	}
+
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *CSVWriter) header() error {
+	if cw.started || !cw.opts.Header {
+		return nil
+	}
+	cw.started = true
+
+	name := func(ref, fallback string) string {
+		if _, err := strconv.Atoi(ref); ref == "" || ref == "-" || err == nil {
+			return fallback
+		}
+		return ref
+	}
+
+	return cw.w.Write(cw.record(
+		name(cw.opts.SourceColumn, "source"),
+		name(cw.opts.PredicateColumn, "predicate"),
+		name(cw.opts.TargetColumn, "target"),
+		name(cw.opts.WeightColumn, "weight"),
+	))
+}
+
+func (cw *CSVWriter) record(source, predicate, target, weight string) []string {
+	if cw.opts.Predicate != "" && cw.opts.PredicateColumn == "" {
+		return []string{source, target, weight}
+	}
+	return []string{source, predicate, target, weight}
+}
+
+// lineReader returns at most one line per Read so that the lines read by the
+// csv.Reader, which reads lines using a bufio.Reader, are the lines of the
+// records read so far.
+type lineReader struct {
+	r      *bufio.Reader
+	lines  int
+	inLine bool
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := lr.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		p[n] = b
+		n++
+		lr.inLine = b != '\n'
+		if !lr.inLine {
+			lr.lines++
+			break
+		}
+	}
+	return n, nil
+}
+
+// last returns the number of the last line read.
+func (lr *lineReader) last() int {
+	if lr.inLine {
+		return lr.lines + 1
+	}
+	return lr.lines
+}
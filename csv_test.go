@@ -0,0 +1,167 @@
+package fabric_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_ImportCSV(suite *testing.T) {
+	suite.Parallel()
+
+	cases := []struct {
+		title    string
+		input    string
+		opts     fabric.CSVOptions
+		expected []fabric.Triple
+		failed   map[int]int // index -> line
+		err      bool
+	}{
+		{
+			title: "Positional",
+			input: "Bob,knows,John,0.5\nJohn,knows,Alice\n",
+			expected: []fabric.Triple{
+				{Source: "Bob", Predicate: "knows", Target: "John", Weight: 0.5},
+				{Source: "John", Predicate: "knows", Target: "Alice"},
+			},
+		},
+		{
+			title: "HeaderColumns",
+			input: "score,dst,src,rel\n1,John,Bob,knows\n\"2\", Alice ,John,likes\n",
+			opts: fabric.CSVOptions{
+				Header:          true,
+				SourceColumn:    "src",
+				PredicateColumn: "REL",
+				TargetColumn:    "dst",
+				WeightColumn:    "score",
+			},
+			expected: []fabric.Triple{
+				{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1},
+				{Source: "John", Predicate: "likes", Target: "Alice", Weight: 2},
+			},
+		},
+		{
+			title: "TSVWithConstantPredicate",
+			input: "Bob\tJohn\t3\nJohn\tAlice\n",
+			opts:  fabric.CSVOptions{Comma: '\t', Predicate: "follows"},
+			expected: []fabric.Triple{
+				{Source: "Bob", Predicate: "follows", Target: "John", Weight: 3},
+				{Source: "John", Predicate: "follows", Target: "Alice"},
+			},
+		},
+		{
+			title: "ConstantPredicateFallback",
+			input: "Bob,,John\nJohn,likes,Alice\n",
+			opts:  fabric.CSVOptions{Predicate: "knows", PredicateColumn: "1"},
+			expected: []fabric.Triple{
+				{Source: "Bob", Predicate: "knows", Target: "John"},
+				{Source: "John", Predicate: "likes", Target: "Alice"},
+			},
+		},
+		{
+			title: "LineErrors",
+			input: "src,rel,dst,score\nBob,knows,John,1\nBob,knows\n? ,knows,John,1\nJohn,knows,Alice,heavy\n\nBob,knows,John,2\nJohn,knows,Alice,3\n",
+			opts:  fabric.CSVOptions{Header: true},
+			expected: []fabric.Triple{
+				{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1},
+				{Source: "John", Predicate: "knows", Target: "Alice", Weight: 3},
+			},
+			failed: map[int]int{1: 3, 2: 4, 3: 5, 4: 7},
+		},
+		{
+			title: "MultiLineField",
+			input: "Bob,knows,\"John\nSmith\",1\r\n\r\n? ,knows,John,1\nJohn,knows,\"Alice\r\nJones\",x\n",
+			opts:  fabric.CSVOptions{},
+			expected: []fabric.Triple{
+				{Source: "Bob", Predicate: "knows", Target: "John\nSmith", Weight: 1},
+			},
+			failed: map[int]int{1: 4, 2: 5},
+		},
+		{
+			title: "UnknownColumn",
+			input: "src,rel,dst\nBob,knows,John\n",
+			opts:  fabric.CSVOptions{Header: true, SourceColumn: "from"},
+			err:   true,
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			fab := fabric.New(&fabric.InMemoryStore{})
+			res, err := fab.ImportCSV(context.Background(), strings.NewReader(cs.input), cs.opts)
+			if cs.err {
+				if err == nil {
+					t.Errorf("expecting error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Inserted != len(cs.expected) || len(res.Failed) != len(cs.failed) {
+				t.Errorf("expected %d inserts and %d failures, got %+v", len(cs.expected), len(cs.failed), res)
+			}
+
+			for _, be := range res.Failed {
+				var le *fabric.LineError
+				if !errors.As(be.Err, &le) || le.Line != cs.failed[be.Index] {
+					t.Errorf("expected failure of item %d at line %d, got %v", be.Index, cs.failed[be.Index], be.Err)
+				}
+			}
+
+			assertTriples(t, fab, cs.expected)
+		})
+	}
+
+	suite.Run("ValidationErrors", func(t *testing.T) {
+		fab := fabric.New(&fabric.InMemoryStore{})
+		res, err := fab.ImportCSV(context.Background(), strings.NewReader("Bob,knows,{\n"), fabric.CSVOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(res.Failed) != 1 || !errors.Is(res.Failed[0].Err, fabric.ErrInvalidTriple) {
+			t.Fatalf("expecting ErrInvalidTriple failure, got %+v", res)
+		}
+
+		data, _ := json.Marshal(res.Failed[0])
+		expected := `{"error":"line 1: invalid target '{': must not contain any of '? {}()'","index":0,"line":1}`
+		if string(data) != expected {
+			t.Errorf("expected %s, got %s", expected, data)
+		}
+	})
+}
+
+func TestFabric_ExportCSV(t *testing.T) {
+	triples := []fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: "John", Weight: 0.5},
+		{Source: "John", Predicate: "knows", Target: `"a, b"`, Weight: 2},
+	}
+
+	fab := fabric.New(&fabric.InMemoryStore{})
+	insert(t, fab, triples...)
+
+	var buf bytes.Buffer
+	opts := fabric.CSVOptions{Header: true, SourceColumn: "src", WeightColumn: "score"}
+	if err := fab.ExportCSV(context.Background(), &buf, fabric.Query{}, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "src,predicate,target,score\nBob,knows,John,0.5\nJohn,knows,\"\"\"a, b\"\"\",2\n"
+	if buf.String() != expected {
+		t.Errorf("expected '%s', got '%s'", expected, buf.String())
+	}
+
+	imported := fabric.New(&fabric.InMemoryStore{})
+	if _, err := imported.ImportCSV(context.Background(), &buf, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertTriples(t, imported, triples)
+}
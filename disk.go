@@ -0,0 +1,565 @@
+package fabric
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	_ Store        = &DiskStore{}
+	_ Counter      = &DiskStore{}
+	_ ReWeighter   = &DiskStore{}
+	_ Upserter     = &DiskStore{}
+	_ BulkInserter = &DiskStore{}
+	_ Returner     = &DiskStore{}
+)
+
+// SyncPolicy controls when writes to the write-ahead log are flushed to
+// stable storage using fsync.
+type SyncPolicy int
+
+// Sync policies supported by DiskStore.
+const (
+	// SyncAlways fsyncs the log after every write operation. No acknowledged
+	// write is lost on a crash.
+	SyncAlways SyncPolicy = iota
+
+	// SyncInterval fsyncs the log periodically in the background. Writes
+	// acknowledged within the last interval may be lost on a crash.
+	SyncInterval
+
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// DiskOptions can be used to configure a DiskStore.
+type DiskOptions struct {
+	// Sync is the fsync policy for the write-ahead log.
+	Sync SyncPolicy
+
+	// SyncInterval is the interval between fsyncs when Sync is SyncInterval.
+	// Defaults to 1 second.
+	SyncInterval time.Duration
+
+	// SnapshotEvery is the number of log records after which a snapshot is
+	// taken and the log is truncated. Defaults to 10000. Negative value
+	// disables automatic snapshots.
+	SnapshotEvery int
+}
+
+// DiskStore implements the Store interface by keeping all the triples in an
+// InMemoryStore and persisting every change to an append-only write-ahead
+// log on disk. The log is periodically compacted into a snapshot. On open,
+// the snapshot is loaded and the log is replayed to recover the state.
+type DiskStore struct {
+	dir  string
+	opts DiskOptions
+	mem  *InMemoryStore
+
+	// wal, walBuf, walRecords and failed are guarded by mem.mu.
+	wal        *os.File
+	walBuf     *bufio.Writer
+	walRecords int
+	failed     error
+
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// errStoreClosed is returned by the writes to a closed DiskStore.
+var errStoreClosed = errors.New("store is closed")
+
+// OpenDiskStore opens the store in the given directory, creating it if it
+// does not exist, and recovers the state from the snapshot and the log.
+func OpenDiskStore(dir string, opts DiskOptions) (*DiskStore, error) {
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Second
+	}
+
+	if opts.SnapshotEvery == 0 {
+		opts.SnapshotEvery = 10000
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	ds := &DiskStore{
+		dir:  dir,
+		opts: opts,
+		mem:  &InMemoryStore{},
+		stop: make(chan struct{}),
+	}
+	ds.mem.ensureInit()
+
+	if err := ds.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	if err := ds.replayWAL(); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(ds.walPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ds.wal = wal
+	ds.walBuf = bufio.NewWriter(wal)
+
+	if opts.Sync == SyncInterval {
+		ds.wg.Add(1)
+		go ds.syncLoop()
+	}
+
+	return ds, nil
+}
+
+// Count returns the number of triples in the store matching the given query.
+func (ds *DiskStore) Count(ctx context.Context, query Query) (int, error) {
+	return ds.mem.Count(ctx, query)
+}
+
+// Query returns all the triples matching the given query.
+func (ds *DiskStore) Query(ctx context.Context, query Query) ([]Triple, error) {
+	return ds.mem.Query(ctx, query)
+}
+
+// Insert logs and stores the triple. Returns ErrConflict if the triple
+// already exists.
+func (ds *DiskStore) Insert(ctx context.Context, tri Triple) error {
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	if _, found := ds.mem.data[ds.mem.idFor(tri)]; found {
+		return ErrConflict
+	}
+
+	return ds.apply(walRecord{Op: walPut, Triple: tri})
+}
+
+// InsertMany logs and stores all the triples that do not already exist
+// using a single log write.
+func (ds *DiskStore) InsertMany(ctx context.Context, triples []Triple) ([]BatchError, error) {
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	var failed []BatchError
+	var records []walRecord
+	seen := map[string]bool{}
+	for i, tri := range triples {
+		id := ds.mem.idFor(tri)
+		if _, found := ds.mem.data[id]; found || seen[id] {
+			failed = append(failed, BatchError{Index: i, Err: ErrConflict})
+			continue
+		}
+
+		seen[id] = true
+		records = append(records, walRecord{Op: walPut, Triple: tri})
+	}
+
+	if err := ds.apply(records...); err != nil {
+		return nil, err
+	}
+
+	return failed, nil
+}
+
+// Upsert logs and stores the triple, resolving a conflict with an existing
+// triple using the given policy.
+func (ds *DiskStore) Upsert(ctx context.Context, tri Triple, policy ConflictPolicy) error {
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	if existing, found := ds.mem.data[ds.mem.idFor(tri)]; found {
+		tri.Weight = policy.resolve(existing.Weight, tri.Weight)
+		if policy == KeepExisting || len(tri.Properties) == 0 {
+			tri.Properties = existing.Properties
+		}
+	}
+
+	return ds.apply(walRecord{Op: walPut, Triple: tri})
+}
+
+// Delete logs and removes all the triples that match the given query.
+func (ds *DiskStore) Delete(ctx context.Context, query Query) (int, error) {
+	triples, err := ds.DeleteReturning(ctx, query)
+	return len(triples), err
+}
+
+// DeleteReturning logs and removes all the triples that match the given
+// query and returns them.
+func (ds *DiskStore) DeleteReturning(ctx context.Context, query Query) ([]Triple, error) {
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	if query.IsAny() {
+		return nil, errNoClause
+	}
+
+	triples, err := ds.mem.matching(query)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]walRecord, len(triples))
+	for i, tri := range triples {
+		records[i] = walRecord{Op: walDelete, Triple: tri}
+	}
+
+	if err := ds.apply(records...); err != nil {
+		return nil, err
+	}
+
+	return triples, nil
+}
+
+// ReWeight logs and re-weights all the triples matching the query.
+func (ds *DiskStore) ReWeight(ctx context.Context, query Query, delta float64, replace bool) (int, error) {
+	triples, err := ds.ReWeightReturning(ctx, query, delta, replace)
+	return len(triples), err
+}
+
+// ReWeightReturning logs and re-weights all the triples matching the query
+// and returns the updated triples.
+func (ds *DiskStore) ReWeightReturning(ctx context.Context, query Query, delta float64, replace bool) ([]Triple, error) {
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	triples, err := ds.mem.matching(query)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]walRecord, len(triples))
+	for i, tri := range triples {
+		triples[i] = reweighted(tri, delta, replace)
+		records[i] = walRecord{Op: walPut, Triple: triples[i]}
+	}
+
+	if err := ds.apply(records...); err != nil {
+		return nil, err
+	}
+
+	return triples, nil
+}
+
+// Snapshot writes all the triples to a new snapshot file and truncates the
+// write-ahead log.
+func (ds *DiskStore) Snapshot() error {
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	if ds.failed != nil {
+		return ds.failed
+	}
+
+	return ds.snapshot()
+}
+
+// Close flushes and closes the write-ahead log. Writes fail after the store
+// is closed. Calling Close more than once returns the result of the first
+// call.
+func (ds *DiskStore) Close() error {
+	ds.closeOnce.Do(func() {
+		ds.closeErr = ds.close()
+	})
+	return ds.closeErr
+}
+
+func (ds *DiskStore) close() error {
+	close(ds.stop)
+	ds.wg.Wait()
+
+	ds.mem.mu.Lock()
+	defer ds.mem.mu.Unlock()
+
+	if ds.failed == nil {
+		ds.failed = errStoreClosed
+	}
+
+	if err := ds.walBuf.Flush(); err != nil {
+		ds.wal.Close()
+		return err
+	}
+
+	if err := ds.wal.Sync(); err != nil {
+		ds.wal.Close()
+		return err
+	}
+
+	return ds.wal.Close()
+}
+
+// apply writes the records to the log and then applies them to the in-memory
+// state. If writing to the log fails, the log may hold some of the records
+// that are not applied. So, the store is marked as failed and all further
+// writes are refused. Expects the caller to hold the write lock.
+func (ds *DiskStore) apply(records ...walRecord) error {
+	if ds.failed != nil {
+		return ds.failed
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	lines := make([][]byte, len(records))
+	for i, rec := range records {
+		line, err := rec.encode()
+		if err != nil {
+			return err
+		}
+		lines[i] = line
+	}
+
+	if err := ds.writeWAL(lines); err != nil {
+		ds.failed = fmt.Errorf("write-ahead log failed: %w", err)
+		return ds.failed
+	}
+
+	for _, rec := range records {
+		rec.applyTo(ds.mem)
+	}
+	ds.walRecords += len(records)
+
+	if ds.opts.SnapshotEvery > 0 && ds.walRecords >= ds.opts.SnapshotEvery {
+		// the records are already durable in the log. so, a failed snapshot
+		// does not fail the write and will be retried on the next write.
+		_ = ds.snapshot()
+	}
+
+	return nil
+}
+
+func (ds *DiskStore) writeWAL(lines [][]byte) error {
+	for _, line := range lines {
+		if _, err := ds.walBuf.Write(line); err != nil {
+			return err
+		}
+	}
+
+	if err := ds.walBuf.Flush(); err != nil {
+		return err
+	}
+
+	if ds.opts.Sync == SyncAlways {
+		return ds.wal.Sync()
+	}
+	return nil
+}
+
+func (ds *DiskStore) snapshot() error {
+	tmpPath := ds.snapshotPath() + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	buf := bufio.NewWriter(f)
+	enc := json.NewEncoder(buf)
+	for _, tri := range ds.mem.data {
+		if err := enc.Encode(tri); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, ds.snapshotPath()); err != nil {
+		return err
+	}
+	syncDir(ds.dir)
+
+	// records in the log are idempotent. so, a crash before the truncation
+	// below only results in them being replayed over the new snapshot.
+	if err := ds.walBuf.Flush(); err != nil {
+		return err
+	}
+
+	if err := ds.wal.Truncate(0); err != nil {
+		return err
+	}
+
+	ds.walRecords = 0
+	return ds.wal.Sync()
+}
+
+func (ds *DiskStore) loadSnapshot() error {
+	f, err := os.Open(ds.snapshotPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var tri Triple
+		if err := dec.Decode(&tri); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("corrupt snapshot: %v", err)
+		}
+
+		ds.mem.put(tri)
+	}
+}
+
+// replayWAL applies all the records in the log to the in-memory state. A
+// torn record at the end of the log (e.g., due to a crash in the middle of
+// a write) is discarded and the log is truncated to the last good record.
+func (ds *DiskStore) replayWAL() error {
+	data, err := ioutil.ReadFile(ds.walPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	offset := 0
+	for offset < len(data) {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			break // incomplete record at the end
+		}
+
+		rec, err := decodeWALRecord(data[offset : offset+end])
+		if err != nil {
+			if offset+end+1 < len(data) {
+				return fmt.Errorf("corrupt write-ahead log at offset %d: %v", offset, err)
+			}
+			break // torn record at the end
+		}
+
+		rec.applyTo(ds.mem)
+		ds.walRecords++
+		offset += end + 1
+	}
+
+	if offset < len(data) {
+		return os.Truncate(ds.walPath(), int64(offset))
+	}
+	return nil
+}
+
+func (ds *DiskStore) syncLoop() {
+	defer ds.wg.Done()
+
+	ticker := time.NewTicker(ds.opts.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ds.stop:
+			return
+
+		case <-ticker.C:
+			ds.mem.mu.Lock()
+			ds.wal.Sync()
+			ds.mem.mu.Unlock()
+		}
+	}
+}
+
+func (ds *DiskStore) walPath() string {
+	return filepath.Join(ds.dir, "wal.log")
+}
+
+func (ds *DiskStore) snapshotPath() string {
+	return filepath.Join(ds.dir, "snapshot.json")
+}
+
+const (
+	walPut    = "put"
+	walDelete = "del"
+)
+
+// walRecord is a single entry in the write-ahead log. Records describe the
+// resulting state of a triple and hence are idempotent. Every record is
+// written as a line of the form '<crc32 in hex> <json>'.
+type walRecord struct {
+	Op     string `json:"op"`
+	Triple Triple `json:"triple"`
+}
+
+func (rec walRecord) encode() ([]byte, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(data), data)), nil
+}
+
+func (rec walRecord) applyTo(mem *InMemoryStore) {
+	if rec.Op == walDelete {
+		mem.remove(rec.Triple)
+	} else {
+		mem.put(rec.Triple)
+	}
+}
+
+func decodeWALRecord(line []byte) (*walRecord, error) {
+	if len(line) < 10 || line[8] != ' ' {
+		return nil, errors.New("malformed record")
+	}
+
+	var sum uint32
+	if _, err := fmt.Sscanf(string(line[:8]), "%08x", &sum); err != nil {
+		return nil, errors.New("malformed checksum")
+	}
+
+	data := line[9:]
+	if crc32.ChecksumIEEE(data) != sum {
+		return nil, errors.New("checksum mismatch")
+	}
+
+	var rec walRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+
+	if rec.Op != walPut && rec.Op != walDelete {
+		return nil, fmt.Errorf("unknown op '%s'", rec.Op)
+	}
+
+	return &rec, nil
+}
+
+// syncDir fsyncs the directory to persist renames. Errors are ignored since
+// not all platforms support syncing directories.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,144 @@
+package fabric_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestDiskStore(suite *testing.T) {
+	suite.Parallel()
+
+	suite.Run("Recovery", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		fab := fabric.New(openDiskStore(t, dir, fabric.DiskOptions{}))
+		insert(t, fab,
+			fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"},
+			fabric.Triple{Source: "Bob", Predicate: "knows", Target: "Alice"},
+		)
+		fab.ReWeight(context.Background(), fabric.Query{}, 2, true)
+		fab.Delete(context.Background(), fabric.Query{Target: fabric.Clause{Type: "eq", Value: "John"}})
+
+		store := openDiskStore(t, dir, fabric.DiskOptions{})
+		defer store.Close()
+
+		assertTriples(t, fabric.New(store), []fabric.Triple{
+			{Source: "Bob", Predicate: "knows", Target: "Alice", Weight: 2},
+		})
+	})
+
+	suite.Run("TornWrite", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		store := openDiskStore(t, dir, fabric.DiskOptions{Sync: fabric.SyncNever})
+		insert(t, fabric.New(store), fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"})
+		store.Close()
+
+		f, err := os.OpenFile(filepath.Join(dir, "wal.log"), os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("failed to open wal: %v", err)
+		}
+		f.WriteString(`00000000 {"op":"put","triple":{"sou`)
+		f.Close()
+
+		store = openDiskStore(t, dir, fabric.DiskOptions{})
+		fab := fabric.New(store)
+		insert(t, fab, fabric.Triple{Source: "Bob", Predicate: "knows", Target: "Alice"})
+		store.Close()
+
+		store = openDiskStore(t, dir, fabric.DiskOptions{})
+		defer store.Close()
+
+		count, err := fabric.New(store).Count(context.Background(), fabric.Query{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 2 {
+			t.Errorf("expected 2 triples after recovery, got %d", count)
+		}
+	})
+
+	suite.Run("Close", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		store := openDiskStore(t, dir, fabric.DiskOptions{Sync: fabric.SyncInterval})
+		if err := store.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := store.Close(); err != nil {
+			t.Errorf("unexpected error on second close: %v", err)
+		}
+
+		err := store.Insert(context.Background(), fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"})
+		if err == nil {
+			t.Errorf("expecting error on insert after close, got nil")
+		}
+	})
+
+	suite.Run("CorruptLog", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		err := ioutil.WriteFile(filepath.Join(dir, "wal.log"), []byte("garbage\n00000000 {}\n"), 0644)
+		if err != nil {
+			t.Fatalf("failed to write wal: %v", err)
+		}
+
+		if _, err := fabric.OpenDiskStore(dir, fabric.DiskOptions{}); err == nil {
+			t.Errorf("expecting error, got nil")
+		}
+	})
+
+	suite.Run("Snapshot", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		store := openDiskStore(t, dir, fabric.DiskOptions{SnapshotEvery: 2})
+		fab := fabric.New(store)
+		insert(t, fab,
+			fabric.Triple{Source: "a", Predicate: "b", Target: "c"},
+			fabric.Triple{Source: "a", Predicate: "b", Target: "d"},
+			fabric.Triple{Source: "a", Predicate: "b", Target: "e"},
+		)
+		store.Close()
+
+		if _, err := os.Stat(filepath.Join(dir, "snapshot.json")); err != nil {
+			t.Fatalf("expecting snapshot to exist: %v", err)
+		}
+
+		store = openDiskStore(t, dir, fabric.DiskOptions{})
+		defer store.Close()
+
+		count, err := fabric.New(store).Count(context.Background(), fabric.Query{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 3 {
+			t.Errorf("expected 3 triples after recovery, got %d", count)
+		}
+	})
+}
+
+func openDiskStore(t *testing.T, dir string, opts fabric.DiskOptions) *fabric.DiskStore {
+	store, err := fabric.OpenDiskStore(dir, opts)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	return store
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "fabric")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	return dir
+}
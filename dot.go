@@ -0,0 +1,178 @@
+package fabric
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// DOTOptions configures the output of a DOTWriter.
+type DOTOptions struct {
+	// Undirected writes an undirected 'graph' instead of a 'digraph'.
+	Undirected bool
+
+	// ColorByPredicate gives the edges of every predicate a distinct color.
+	ColorByPredicate bool
+
+	// PenWidthScale, if non-zero, sets the pen width of every edge to
+	// 1 + |weight| * PenWidthScale.
+	PenWidthScale float64
+
+	// Cluster, if set, returns the cluster of a node. Nodes with the same
+	// non-empty cluster are grouped into a cluster subgraph.
+	Cluster func(node string) string
+}
+
+// dotPalette is the set of colors used for coloring edges by predicate.
+var dotPalette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+// DOTWriter writes triples as the edges of a Graphviz DOT graph. Names are
+// quoted and escaped as required. Writes are buffered and Flush must be
+// called after the last triple to complete the graph.
+type DOTWriter struct {
+	w       *bufio.Writer
+	name    string
+	opts    DOTOptions
+	started bool
+
+	colors   map[string]string
+	seen     map[string]bool
+	clusters map[string][]string
+	order    []string
+}
+
+// NewDOTWriter returns a writer writing the graph with the given name to w.
+func NewDOTWriter(w io.Writer, name string, opts DOTOptions) *DOTWriter {
+	return &DOTWriter{
+		w:        bufio.NewWriter(w),
+		name:     graphName(name),
+		opts:     opts,
+		colors:   map[string]string{},
+		seen:     map[string]bool{},
+		clusters: map[string][]string{},
+	}
+}
+
+// Write writes the triple as an edge from the source to the target labelled
+// with the predicate. Negative weights are not written as the edge weight
+// since Graphviz does not support them.
+func (dw *DOTWriter) Write(tri Triple) error {
+	dw.header()
+	dw.addNode(tri.Source)
+	dw.addNode(tri.Target)
+
+	op := "->"
+	if dw.opts.Undirected {
+		op = "--"
+	}
+
+	attrs := []string{"label=" + dotID(tri.Predicate, true)}
+	if tri.Weight >= 0 {
+		attrs = append(attrs, fmt.Sprintf("weight=%f", tri.Weight))
+	}
+
+	if dw.opts.ColorByPredicate {
+		attrs = append(attrs, "color="+dotID(dw.color(tri.Predicate), true))
+	}
+
+	if dw.opts.PenWidthScale != 0 {
+		attrs = append(attrs, fmt.Sprintf("penwidth=%.2f", 1+math.Abs(tri.Weight*dw.opts.PenWidthScale)))
+	}
+
+	_, err := fmt.Fprintf(dw.w, "  %s %s %s [%s];\n", dotID(tri.Source, true), op, dotID(tri.Target, true), strings.Join(attrs, " "))
+	return err
+}
+
+// Flush writes the cluster subgraphs, completes the graph and writes any
+// buffered data to the underlying writer. No triples can be written after
+// Flush.
+func (dw *DOTWriter) Flush() error {
+	dw.header()
+
+	for _, cluster := range dw.order {
+		fmt.Fprintf(dw.w, "  subgraph %s {\n", dotID("cluster_"+cluster, true))
+		fmt.Fprintf(dw.w, "    label=%s;\n", dotID(cluster, true))
+		for _, node := range dw.clusters[cluster] {
+			fmt.Fprintf(dw.w, "    %s;\n", dotID(node, true))
+		}
+		dw.w.WriteString("  }\n")
+	}
+
+	dw.w.WriteString("}\n")
+	return dw.w.Flush()
+}
+
+func (dw *DOTWriter) header() {
+	if dw.started {
+		return
+	}
+	dw.started = true
+
+	kind := "digraph"
+	if dw.opts.Undirected {
+		kind = "graph"
+	}
+	fmt.Fprintf(dw.w, "%s %s {\n", kind, dotID(dw.name, false))
+}
+
+func (dw *DOTWriter) addNode(node string) {
+	if dw.opts.Cluster == nil || dw.seen[node] {
+		return
+	}
+	dw.seen[node] = true
+
+	cluster := dw.opts.Cluster(node)
+	if cluster == "" {
+		return
+	}
+
+	if _, found := dw.clusters[cluster]; !found {
+		dw.order = append(dw.order, cluster)
+	}
+	dw.clusters[cluster] = append(dw.clusters[cluster], node)
+}
+
+func (dw *DOTWriter) color(predicate string) string {
+	c, found := dw.colors[predicate]
+	if !found {
+		c = dotPalette[len(dw.colors)%len(dotPalette)]
+		dw.colors[predicate] = c
+	}
+	return c
+}
+
+// dotID returns s as a DOT identifier. The identifier is quoted (with quotes
+// and backslashes escaped) if quote is true or if s is not a valid unquoted
+// identifier.
+func dotID(s string, quote bool) string {
+	if !quote && isDOTName(s) {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
+
+func isDOTName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+
+	switch strings.ToLower(s) {
+	case "node", "edge", "graph", "digraph", "subgraph", "strict":
+		return false
+	}
+
+	for _, r := range s {
+		isAlpha := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isAlpha && r != '_' && !(r >= '0' && r <= '9') && r < 0x80 {
+			return false
+		}
+	}
+	return true
+}
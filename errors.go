@@ -0,0 +1,85 @@
+package fabric
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotSupported is returned when an operation is not supported.
+	ErrNotSupported = errors.New("not supported")
+
+	// ErrTxDone is returned when an operation is performed on a transaction
+	// that has already been committed or rolled back.
+	ErrTxDone = errors.New("transaction has already been committed or rolled back")
+
+	// ErrConflict is returned when a triple being inserted already exists.
+	ErrConflict = errors.New("triple already exists")
+
+	// ErrNotFound is returned (possibly wrapped) when the requested entity
+	// does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidTriple is returned (wrapped in an InvalidTripleError) when a
+	// triple fails validation.
+	ErrInvalidTriple = errors.New("invalid triple")
+
+	// ErrUnsupportedClause is returned (wrapped) when a store does not
+	// support the type of a query clause.
+	ErrUnsupportedClause = errors.New("unsupported clause type")
+
+	// ErrInvalidQuery is returned (wrapped) when a query is not valid (e.g.,
+	// ordering by an unknown field).
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidClause is returned (wrapped) when the value of a query clause
+	// is not valid for its field (e.g., a non-numeric weight).
+	ErrInvalidClause = errors.New("invalid clause")
+)
+
+// errNoClause is returned by Delete when the query has no clauses to avoid
+// accidentally deleting all the triples.
+var errNoClause = fmt.Errorf("%w: no query clause specified", ErrInvalidQuery)
+
+// InvalidTripleError provides information about the field of a triple that
+// failed validation. errors.Is(err, ErrInvalidTriple) reports true for it.
+type InvalidTripleError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *InvalidTripleError) Error() string {
+	return fmt.Sprintf("invalid %s '%s': %s", e.Field, e.Value, e.Reason)
+}
+
+// Is returns true if target is ErrInvalidTriple.
+func (e *InvalidTripleError) Is(target error) bool {
+	return target == ErrInvalidTriple
+}
+
+// LineError is the error for a single line of an imported file.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// notFoundError is an error for which errors.Is(err, ErrNotFound) is true.
+type notFoundError string
+
+func (e notFoundError) Error() string {
+	return string(e)
+}
+
+func (e notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
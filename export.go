@@ -1,21 +1,193 @@
 package fabric
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
-// ExportDOT exports the given set of triples in DOT format.
+// ExportDOT exports the given set of triples in DOT format. See DOTWriter
+// for writing large graphs or styling the output.
 func ExportDOT(name string, triples []Triple) string {
+	var sb strings.Builder
+	dw := NewDOTWriter(&sb, name, DOTOptions{})
+	for _, tri := range triples {
+		dw.Write(tri)
+	}
+	dw.Flush()
+	return sb.String()
+}
+
+// ExportGraphML exports the given set of triples in GraphML format. Nodes
+// have the entity name as label and edges have the predicate as label along
+// with the weight as a double attribute.
+func ExportGraphML(name string, triples []Triple) string {
+	nodes, ids := graphNodes(triples)
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	sb.WriteString(`  <key id="label" for="node" attr.name="label" attr.type="string"/>` + "\n")
+	sb.WriteString(`  <key id="predicate" for="edge" attr.name="label" attr.type="string"/>` + "\n")
+	sb.WriteString(`  <key id="weight" for="edge" attr.name="weight" attr.type="double"/>` + "\n")
+	fmt.Fprintf(&sb, "  <graph id=\"%s\" edgedefault=\"directed\">\n", xmlEscape(graphName(name)))
+
+	for i, node := range nodes {
+		fmt.Fprintf(&sb, "    <node id=\"n%d\"><data key=\"label\">%s</data></node>\n", i, xmlEscape(node))
+	}
+
+	for i, tri := range triples {
+		fmt.Fprintf(&sb, "    <edge id=\"e%d\" source=\"n%d\" target=\"n%d\">", i, ids[tri.Source], ids[tri.Target])
+		fmt.Fprintf(&sb, "<data key=\"predicate\">%s</data><data key=\"weight\">%s</data></edge>\n",
+			xmlEscape(tri.Predicate), formatWeight(tri.Weight))
+	}
+
+	sb.WriteString("  </graph>\n</graphml>\n")
+	return sb.String()
+}
+
+// ExportGEXF exports the given set of triples in GEXF (1.2) format. Nodes
+// have the entity name as label and edges have the predicate as label and
+// the weight as the edge weight. Predicate and weight are also available as
+// edge attributes.
+func ExportGEXF(name string, triples []Triple) string {
+	nodes, ids := graphNodes(triples)
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<gexf xmlns="http://www.gexf.net/1.2draft" version="1.2">` + "\n")
+	fmt.Fprintf(&sb, "  <meta><description>%s</description></meta>\n", xmlEscape(graphName(name)))
+	sb.WriteString(`  <graph mode="static" defaultedgetype="directed">` + "\n")
+	sb.WriteString(`    <attributes class="edge">` + "\n")
+	sb.WriteString(`      <attribute id="predicate" title="predicate" type="string"/>` + "\n")
+	sb.WriteString(`      <attribute id="weight" title="weight" type="double"/>` + "\n")
+	sb.WriteString("    </attributes>\n")
+
+	sb.WriteString("    <nodes>\n")
+	for i, node := range nodes {
+		fmt.Fprintf(&sb, "      <node id=\"n%d\" label=\"%s\"/>\n", i, xmlEscape(node))
+	}
+	sb.WriteString("    </nodes>\n")
+
+	sb.WriteString("    <edges>\n")
+	for i, tri := range triples {
+		predicate, weight := xmlEscape(tri.Predicate), formatWeight(tri.Weight)
+		fmt.Fprintf(&sb, "      <edge id=\"e%d\" source=\"n%d\" target=\"n%d\" label=\"%s\" weight=\"%s\">",
+			i, ids[tri.Source], ids[tri.Target], predicate, weight)
+		fmt.Fprintf(&sb, "<attvalues><attvalue for=\"predicate\" value=\"%s\"/><attvalue for=\"weight\" value=\"%s\"/></attvalues></edge>\n",
+			predicate, weight)
+	}
+	sb.WriteString("    </edges>\n")
+
+	sb.WriteString("  </graph>\n</gexf>\n")
+	return sb.String()
+}
+
+// ExportCytoscape exports the given set of triples as Cytoscape.js elements
+// JSON ({"nodes": [...], "edges": [...]}). Entity names are used as node ids
+// and edges carry the predicate as label along with the weight.
+func ExportCytoscape(triples []Triple) ([]byte, error) {
+	type nodeData struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+	}
+
+	type edgeData struct {
+		ID     string  `json:"id"`
+		Source string  `json:"source"`
+		Target string  `json:"target"`
+		Label  string  `json:"label"`
+		Weight float64 `json:"weight"`
+	}
+
+	type node struct {
+		Data nodeData `json:"data"`
+	}
+
+	type edge struct {
+		Data edgeData `json:"data"`
+	}
+
+	nodes, _ := graphNodes(triples)
+	elements := struct {
+		Nodes []node `json:"nodes"`
+		Edges []edge `json:"edges"`
+	}{
+		Nodes: make([]node, len(nodes)),
+		Edges: make([]edge, len(triples)),
+	}
+
+	for i, name := range nodes {
+		elements.Nodes[i] = node{Data: nodeData{ID: name, Label: name}}
+	}
+
+	for i, tri := range triples {
+		elements.Edges[i] = edge{Data: edgeData{
+			ID:     tri.Source + " " + tri.Predicate + " " + tri.Target,
+			Source: tri.Source,
+			Target: tri.Target,
+			Label:  tri.Predicate,
+			Weight: tri.Weight,
+		}}
+	}
+
+	return json.Marshal(elements)
+}
+
+// ExportMermaid exports the given set of triples as a Mermaid flowchart with
+// the predicates as edge labels.
+func ExportMermaid(triples []Triple) string {
+	nodes, ids := graphNodes(triples)
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, node := range nodes {
+		fmt.Fprintf(&sb, "    n%d[\"%s\"]\n", i, mermaidEscape(node))
+	}
+
+	for _, tri := range triples {
+		fmt.Fprintf(&sb, "    n%d -->|\"%s\"| n%d\n", ids[tri.Source], mermaidEscape(tri.Predicate), ids[tri.Target])
+	}
+	return sb.String()
+}
+
+// mermaidEscape escapes the text for use in a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer("#", "#35;", `"`, "#quot;", "\n", " ", "\r", " ").Replace(s)
+}
+
+func graphName(name string) string {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		name = "fabric"
+		return "fabric"
 	}
+	return name
+}
 
-	out := fmt.Sprintf("digraph %s {\n", name)
+// graphNodes returns the distinct sources and targets of the triples in the
+// order of their first appearance along with their indices.
+func graphNodes(triples []Triple) ([]string, map[string]int) {
+	var nodes []string
+	ids := map[string]int{}
 	for _, tri := range triples {
-		out += fmt.Sprintf("  \"%s\" -> \"%s\" [label=\"%s\" weight=%f];\n", tri.Source, tri.Target, tri.Predicate, tri.Weight)
+		for _, node := range []string{tri.Source, tri.Target} {
+			if _, found := ids[node]; !found {
+				ids[node] = len(nodes)
+				nodes = append(nodes, node)
+			}
+		}
 	}
-	out += "}\n"
-	return out
+	return nodes, ids
+}
+
+func formatWeight(w float64) string {
+	return strconv.FormatFloat(w, 'g', -1, 64)
+}
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
 }
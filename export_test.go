@@ -1,6 +1,7 @@
 package fabric_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/spy16/fabric"
@@ -33,3 +34,188 @@ func TestExportDOT(suite *testing.T) {
 		}
 	})
 }
+
+func TestDOTWriter(suite *testing.T) {
+	suite.Parallel()
+
+	triples := []fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: `John "JJ" \ Doe`, Weight: 2},
+		{Source: "Bob", Predicate: "type", Target: "Person", Weight: -1},
+		{Source: "John", Predicate: "type", Target: "Person\nMale"},
+	}
+
+	cases := []struct {
+		title    string
+		name     string
+		opts     fabric.DOTOptions
+		expected string
+	}{
+		{
+			title: "Escaping",
+			name:  "my graph",
+			expected: `digraph "my graph" {
+  "Bob" -> "John \"JJ\" \\ Doe" [label="knows" weight=2.000000];
+  "Bob" -> "Person" [label="type"];
+  "John" -> "Person\nMale" [label="type" weight=0.000000];
+}
+`,
+		},
+		{
+			title: "Styled",
+			name:  "graph",
+			opts: fabric.DOTOptions{
+				Undirected:       true,
+				ColorByPredicate: true,
+				PenWidthScale:    0.5,
+			},
+			expected: `graph "graph" {
+  "Bob" -- "John \"JJ\" \\ Doe" [label="knows" weight=2.000000 color="#1f77b4" penwidth=2.00];
+  "Bob" -- "Person" [label="type" color="#ff7f0e" penwidth=1.50];
+  "John" -- "Person\nMale" [label="type" weight=0.000000 color="#ff7f0e" penwidth=1.00];
+}
+`,
+		},
+		{
+			title: "Clusters",
+			name:  "people",
+			opts: fabric.DOTOptions{
+				Cluster: func(node string) string {
+					if strings.HasPrefix(node, "Person") {
+						return "types"
+					}
+					return "entities"
+				},
+			},
+			expected: `digraph people {
+  "Bob" -> "John \"JJ\" \\ Doe" [label="knows" weight=2.000000];
+  "Bob" -> "Person" [label="type"];
+  "John" -> "Person\nMale" [label="type" weight=0.000000];
+  subgraph "cluster_entities" {
+    label="entities";
+    "Bob";
+    "John \"JJ\" \\ Doe";
+    "John";
+  }
+  subgraph "cluster_types" {
+    label="types";
+    "Person";
+    "Person\nMale";
+  }
+}
+`,
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			var sb strings.Builder
+			dw := fabric.NewDOTWriter(&sb, cs.name, cs.opts)
+			for _, tri := range triples {
+				if err := dw.Write(tri); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if err := dw.Flush(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if sb.String() != cs.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", cs.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestExportGraphML(t *testing.T) {
+	out := fabric.ExportGraphML("", []fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: "John", Weight: 0.5},
+		{Source: "John", Predicate: "likes", Target: "<Alice & Co>", Weight: -2},
+	})
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="label" for="node" attr.name="label" attr.type="string"/>
+  <key id="predicate" for="edge" attr.name="label" attr.type="string"/>
+  <key id="weight" for="edge" attr.name="weight" attr.type="double"/>
+  <graph id="fabric" edgedefault="directed">
+    <node id="n0"><data key="label">Bob</data></node>
+    <node id="n1"><data key="label">John</data></node>
+    <node id="n2"><data key="label">&lt;Alice &amp; Co&gt;</data></node>
+    <edge id="e0" source="n0" target="n1"><data key="predicate">knows</data><data key="weight">0.5</data></edge>
+    <edge id="e1" source="n1" target="n2"><data key="predicate">likes</data><data key="weight">-2</data></edge>
+  </graph>
+</graphml>
+`
+	if out != expected {
+		t.Errorf("expected '%s', got '%s'", expected, out)
+	}
+}
+
+func TestExportGEXF(t *testing.T) {
+	out := fabric.ExportGEXF("hello", []fabric.Triple{
+		{Source: "Bob", Predicate: "says", Target: `"hi"`, Weight: 1},
+	})
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://www.gexf.net/1.2draft" version="1.2">
+  <meta><description>hello</description></meta>
+  <graph mode="static" defaultedgetype="directed">
+    <attributes class="edge">
+      <attribute id="predicate" title="predicate" type="string"/>
+      <attribute id="weight" title="weight" type="double"/>
+    </attributes>
+    <nodes>
+      <node id="n0" label="Bob"/>
+      <node id="n1" label="&#34;hi&#34;"/>
+    </nodes>
+    <edges>
+      <edge id="e0" source="n0" target="n1" label="says" weight="1"><attvalues><attvalue for="predicate" value="says"/><attvalue for="weight" value="1"/></attvalues></edge>
+    </edges>
+  </graph>
+</gexf>
+`
+	if out != expected {
+		t.Errorf("expected '%s', got '%s'", expected, out)
+	}
+}
+
+func TestExportCytoscape(t *testing.T) {
+	data, err := fabric.ExportCytoscape([]fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: "John", Weight: 0.5},
+		{Source: "John", Predicate: "knows", Target: "Bob"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"nodes":[{"data":{"id":"Bob","label":"Bob"}},{"data":{"id":"John","label":"John"}}],` +
+		`"edges":[{"data":{"id":"Bob knows John","source":"Bob","target":"John","label":"knows","weight":0.5}},` +
+		`{"data":{"id":"John knows Bob","source":"John","target":"Bob","label":"knows","weight":0}}]}`
+	if string(data) != expected {
+		t.Errorf("expected '%s', got '%s'", expected, data)
+	}
+
+	empty, _ := fabric.ExportCytoscape(nil)
+	if string(empty) != `{"nodes":[],"edges":[]}` {
+		t.Errorf("expected empty elements, got '%s'", empty)
+	}
+}
+
+func TestExportMermaid(t *testing.T) {
+	out := fabric.ExportMermaid([]fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: "John"},
+		{Source: "Bob", Predicate: "name#1", Target: `"Bob"@en`},
+	})
+
+	expected := `flowchart LR
+    n0["Bob"]
+    n1["John"]
+    n2["#quot;Bob#quot;@en"]
+    n0 -->|"knows"| n1
+    n0 -->|"name#35;1"| n2
+`
+	if out != expected {
+		t.Errorf("expected '%s', got '%s'", expected, out)
+	}
+}
@@ -2,12 +2,12 @@ package fabric
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 )
 
-// ErrNotSupported is returned when an operation is not supported.
-var ErrNotSupported = errors.New("not supported")
-
 // New returns a new instance of fabric with given store implementation.
 func New(store Store) *Fabric {
 	if f, ok := store.(*Fabric); ok {
@@ -16,12 +16,48 @@ func New(store Store) *Fabric {
 
 	f := &Fabric{}
 	f.store = store
+	f.watch = &watchHub{}
 	return f
 }
 
 // Fabric provides functions to query and manage triples.
 type Fabric struct {
 	store Store
+	watch *watchHub
+
+	// pending collects the events within a batch until it is committed.
+	pending *[]Event
+}
+
+// BatchResult represents the outcome of a bulk operation.
+type BatchResult struct {
+	Inserted int          `json:"inserted"`
+	Failed   []BatchError `json:"failed,omitempty"`
+}
+
+// BatchError represents the failure of a single item in a bulk operation.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (be BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", be.Index, be.Err)
+}
+
+// MarshalJSON encodes the batch error as a JSON object with index and error
+// (and line if the error is a LineError).
+func (be BatchError) MarshalJSON() ([]byte, error) {
+	v := map[string]interface{}{
+		"index": be.Index,
+		"error": be.Err.Error(),
+	}
+
+	var le *LineError
+	if errors.As(be.Err, &le) {
+		v["line"] = le.Line
+	}
+	return json.Marshal(v)
 }
 
 // Insert validates the triple and persists it to the store.
@@ -29,8 +65,82 @@ func (f *Fabric) Insert(ctx context.Context, tri Triple) error {
 	if err := tri.Validate(); err != nil {
 		return err
 	}
+	tri.normalize()
+
+	if err := f.store.Insert(ctx, tri); err != nil {
+		return err
+	}
+
+	f.emit(Event{Type: EventInsert, Triple: tri})
+	return nil
+}
+
+// InsertMany validates all the triples and inserts the valid ones into the
+// store. If the store implements BulkInserter, triples are inserted in bulk.
+// Otherwise, Insert is called for every triple. Triples that could not be
+// inserted are reported in the result along with their index and reason. A
+// non-nil error is returned only if the operation could not be completed.
+func (f *Fabric) InsertMany(ctx context.Context, triples []Triple) (BatchResult, error) {
+	res := BatchResult{}
+
+	var valid []Triple
+	var indices []int
+	for i, tri := range triples {
+		if err := tri.Validate(); err != nil {
+			res.Failed = append(res.Failed, BatchError{Index: i, Err: err})
+			continue
+		}
+
+		tri.normalize()
+		valid = append(valid, tri)
+		indices = append(indices, i)
+	}
+
+	var failed []BatchError
+	if bulk, ok := f.store.(BulkInserter); ok {
+		var err error
+		failed, err = bulk.InsertMany(ctx, valid)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		for i, tri := range valid {
+			if err := ctx.Err(); err != nil {
+				return res, err
+			}
+
+			err := f.store.Insert(ctx, tri)
+			if errors.Is(err, ErrConflict) || errors.Is(err, ErrInvalidTriple) {
+				failed = append(failed, BatchError{Index: i, Err: err})
+			} else if err != nil {
+				return res, err
+			}
+		}
+	}
+
+	isFailed := map[int]bool{}
+	for _, be := range failed {
+		isFailed[be.Index] = true
+		be.Index = indices[be.Index]
+		res.Failed = append(res.Failed, be)
+	}
+	sort.Slice(res.Failed, func(i, j int) bool {
+		return res.Failed[i].Index < res.Failed[j].Index
+	})
+
+	res.Inserted = len(triples) - len(res.Failed)
+
+	if f.watching() {
+		events := make([]Event, 0, res.Inserted)
+		for i, tri := range valid {
+			if !isFailed[i] {
+				events = append(events, Event{Type: EventInsert, Triple: tri})
+			}
+		}
+		f.emit(events...)
+	}
 
-	return f.store.Insert(ctx, tri)
+	return res, nil
 }
 
 // Query finds all the triples matching the given query.
@@ -39,10 +149,38 @@ func (f *Fabric) Query(ctx context.Context, query Query) ([]Triple, error) {
 	return f.store.Query(ctx, query)
 }
 
+// Iterate calls fn for every triple matching the query without holding all
+// the results in memory if the store implements the Iterator interface.
+// Otherwise, the results of Query are iterated over. Iteration stops at the
+// first error returned by fn and the error is returned.
+func (f *Fabric) Iterate(ctx context.Context, query Query, fn func(tri Triple) error) error {
+	query.normalize()
+
+	if it, ok := f.store.(Iterator); ok {
+		return it.Iterate(ctx, query, fn)
+	}
+
+	triples, err := f.store.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, tri := range triples {
+		if err := fn(tri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Count returns the number of triples matching the query. If the store does
 // not implement the Counter interface, standard Query method will be used to
-// fetch all triples and the result set length is returned.
+// fetch all triples and the result set length is returned. Limit, Offset and
+// Cursor of the query are ignored.
 func (f *Fabric) Count(ctx context.Context, query Query) (int, error) {
+	query.normalize()
+	query = query.unpaged()
+
 	counter, ok := f.store.(Counter)
 	if ok {
 		return counter.Count(ctx, query)
@@ -57,13 +195,44 @@ func (f *Fabric) Count(ctx context.Context, query Query) (int, error) {
 }
 
 // Delete removes all the triples from the store matching the given query and
-// returns the number of items deleted.
+// returns the number of items deleted. Limit, Offset and Cursor of the query
+// are ignored. Queries without any clauses are rejected with ErrInvalidQuery
+// to avoid deleting all the triples by accident.
 func (f *Fabric) Delete(ctx context.Context, query Query) (int, error) {
-	return f.store.Delete(ctx, query)
+	query.normalize()
+	query = query.unpaged()
+
+	if ret, ok := f.store.(Returner); ok && f.watching() {
+		deleted, err := ret.DeleteReturning(ctx, query)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, tri := range deleted {
+			f.emit(Event{Type: EventDelete, Triple: tri})
+		}
+		return len(deleted), nil
+	}
+
+	affected, err := f.affected(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := f.store.Delete(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tri := range affected {
+		f.emit(Event{Type: EventDelete, Triple: tri})
+	}
+	return count, nil
 }
 
 // ReWeight performs weight updates on all triples matching the query, if the
 // store implements ReWeighter interface. Otherwise, returns ErrNotSupported.
+// Limit, Offset and Cursor of the query are ignored.
 func (f *Fabric) ReWeight(ctx context.Context, query Query, delta float64, replace bool) (int, error) {
 	if delta == 0 && !replace {
 		// adding delta has no effect since it is zero
@@ -74,6 +243,81 @@ func (f *Fabric) ReWeight(ctx context.Context, query Query, delta float64, repla
 	if !ok {
 		return 0, ErrNotSupported
 	}
+	query.normalize()
+	query = query.unpaged()
+
+	if ret, ok := f.store.(Returner); ok && f.watching() {
+		updated, err := ret.ReWeightReturning(ctx, query, delta, replace)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, tri := range updated {
+			f.emit(Event{Type: EventReWeight, Triple: tri})
+		}
+		return len(updated), nil
+	}
+
+	affected, err := f.affected(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := rew.ReWeight(ctx, query, delta, replace)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tri := range affected {
+		f.emit(Event{Type: EventReWeight, Triple: reweighted(tri, delta, replace)})
+	}
+	return count, nil
+}
+
+// affected returns the triples that would be affected by a change made using
+// the query if there are any subscribers for the change events. It is used
+// for stores not implementing Returner and the result may differ from the
+// actual change under concurrent writes.
+func (f *Fabric) affected(ctx context.Context, query Query) ([]Triple, error) {
+	if !f.watching() {
+		return nil, nil
+	}
+
+	return f.store.Query(ctx, query)
+}
+
+// Batch runs fn within a transaction if the store implements the Transactor
+// interface. Otherwise, returns ErrNotSupported. All the changes made using
+// the Fabric passed to fn are committed if fn returns nil and rolled back if
+// it returns an error or panics.
+func (f *Fabric) Batch(ctx context.Context, fn func(tx *Fabric) error) error {
+	tr, ok := f.store.(Transactor)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	tx, err := tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	txf := &Fabric{store: tx, watch: f.watch, pending: &[]Event{}}
+	if err := fn(txf); err != nil {
+		return err
+	}
+
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
-	return rew.ReWeight(ctx, query, delta, replace)
+	f.emit(*txf.pending...)
+	return nil
 }
@@ -1 +1,258 @@
 package fabric_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_Errors(suite *testing.T) {
+	suite.Parallel()
+
+	if !errors.Is(fabric.ErrNoPath, fabric.ErrNotFound) {
+		suite.Errorf("expecting ErrNoPath to be a not-found error")
+	}
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		suite.Run(name, func(t *testing.T) {
+			fab := fabric.New(newStore())
+			tri := fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"}
+			insert(t, fab, tri)
+
+			if err := fab.Insert(context.Background(), tri); !errors.Is(err, fabric.ErrConflict) {
+				t.Errorf("expecting ErrConflict, got %v", err)
+			}
+
+			if err := fab.Insert(context.Background(), fabric.Triple{Source: "Bob"}); !errors.Is(err, fabric.ErrInvalidTriple) {
+				t.Errorf("expecting ErrInvalidTriple, got %v", err)
+			}
+
+			_, err := fab.Query(context.Background(), fabric.Query{Source: fabric.Clause{Type: "unknown", Value: "Bob"}})
+			if !errors.Is(err, fabric.ErrUnsupportedClause) {
+				t.Errorf("expecting ErrUnsupportedClause, got %v", err)
+			}
+
+			_, err = fab.Query(context.Background(), fabric.Query{Weight: fabric.Clause{Type: "gt", Value: "high"}})
+			if !errors.Is(err, fabric.ErrInvalidClause) {
+				t.Errorf("expecting ErrInvalidClause, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFabric_Batch(suite *testing.T) {
+	suite.Parallel()
+
+	suite.Run("NotSupported", func(t *testing.T) {
+		fab := fabric.New(storeOnly{&fabric.InMemoryStore{}})
+		err := fab.Batch(context.Background(), func(tx *fabric.Fabric) error {
+			return nil
+		})
+
+		if err != fabric.ErrNotSupported {
+			t.Errorf("expected ErrNotSupported, got %v", err)
+		}
+	})
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		suite.Run(name, func(t *testing.T) {
+			t.Run("Commit", func(t *testing.T) {
+				fab := fabric.New(newStore())
+				insert(t, fab, fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"})
+
+				err := fab.Batch(context.Background(), func(tx *fabric.Fabric) error {
+					if err := tx.Insert(context.Background(), fabric.Triple{Source: "Bob", Predicate: "knows", Target: "Alice"}); err != nil {
+						return err
+					}
+
+					if _, err := tx.ReWeight(context.Background(), fabric.Query{}, 2, true); err != nil {
+						return err
+					}
+
+					_, err := tx.Delete(context.Background(), fabric.Query{Target: fabric.Clause{Type: "eq", Value: "John"}})
+					return err
+				})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				assertTriples(t, fab, []fabric.Triple{{Source: "Bob", Predicate: "knows", Target: "Alice", Weight: 2}})
+			})
+
+			t.Run("RollbackOnError", func(t *testing.T) {
+				fab := fabric.New(newStore())
+				insert(t, fab, fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1})
+
+				errFail := errors.New("failed")
+				err := fab.Batch(context.Background(), func(tx *fabric.Fabric) error {
+					tx.Insert(context.Background(), fabric.Triple{Source: "Bob", Predicate: "knows", Target: "Alice"})
+					tx.ReWeight(context.Background(), fabric.Query{}, 5, false)
+					tx.Delete(context.Background(), fabric.Query{Source: fabric.Clause{Type: "eq", Value: "Bob"}})
+
+					return tx.Insert(context.Background(), fabric.Triple{Source: "?", Predicate: "knows", Target: "Alice"})
+				})
+				if err == nil {
+					t.Fatalf("expecting error, got nil")
+				}
+
+				err = fab.Batch(context.Background(), func(tx *fabric.Fabric) error {
+					tx.Insert(context.Background(), fabric.Triple{Source: "Bob", Predicate: "knows", Target: "Alice"})
+					return errFail
+				})
+				if err != errFail {
+					t.Fatalf("expected error '%v', got '%v'", errFail, err)
+				}
+
+				assertTriples(t, fab, []fabric.Triple{{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1}})
+			})
+		})
+	}
+}
+
+func TestFabric_InsertMany(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+		"StoreOnly":     func() fabric.Store { return storeOnly{&fabric.InMemoryStore{}} },
+	})
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		suite.Run(name, func(t *testing.T) {
+			fab := fabric.New(newStore())
+			insert(t, fab, fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"})
+
+			triples := []fabric.Triple{
+				{Source: "Bob", Predicate: "knows", Target: "Alice"},
+				{Source: "Bob", Predicate: "knows", Target: "John"},
+				{Source: "Bob", Predicate: "", Target: "Jane"},
+				{Source: "Alice", Predicate: "knows", Target: "John"},
+				{Source: "Bob", Predicate: "knows", Target: "Alice"},
+			}
+			for i := 0; i < 500; i++ {
+				triples = append(triples, fabric.Triple{Source: "n", Predicate: "p", Target: fmt.Sprintf("n%d", i)})
+			}
+
+			res, err := fab.InsertMany(context.Background(), triples)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Inserted != len(triples)-3 {
+				t.Errorf("expected %d inserts, got %d", len(triples)-3, res.Inserted)
+			}
+
+			var failed []int
+			for _, be := range res.Failed {
+				failed = append(failed, be.Index)
+			}
+			if !reflect.DeepEqual([]int{1, 2, 4}, failed) {
+				t.Errorf("expected failures at [1 2 4], got %v", failed)
+			}
+
+			count, err := fab.Count(context.Background(), fabric.Query{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != len(triples)-2 {
+				t.Errorf("expected %d triples in store, got %d", len(triples)-2, count)
+			}
+		})
+	}
+}
+
+func TestFabric_Iterate(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+		"StoreOnly":     func() fabric.Store { return storeOnly{&fabric.InMemoryStore{}} },
+	})
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		suite.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			fab := fabric.New(newStore())
+			insert(t, fab,
+				fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1},
+				fabric.Triple{Source: "Bob", Predicate: "knows", Target: "Alice", Weight: 3},
+				fabric.Triple{Source: "Alice", Predicate: "knows", Target: "John", Weight: 2},
+			)
+
+			query := fabric.Query{
+				Predicate: fabric.Clause{Type: "equal", Value: "knows"},
+				OrderBy:   []fabric.Order{{Field: "weight", Desc: true}},
+			}
+			expected, err := fab.Query(ctx, query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []fabric.Triple
+			err = fab.Iterate(ctx, query, func(tri fabric.Triple) error {
+				got = append(got, tri)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expected, got) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+
+			errStop := errors.New("stop")
+			visited := 0
+			err = fab.Iterate(ctx, query, func(tri fabric.Triple) error {
+				visited++
+				return errStop
+			})
+			if err != errStop || visited != 1 {
+				t.Errorf("expected iteration to stop with errStop after 1 triple, got %v after %d", err, visited)
+			}
+		})
+	}
+}
+
+func insert(t *testing.T, fab *fabric.Fabric, triples ...fabric.Triple) {
+	for _, tri := range triples {
+		if err := fab.Insert(context.Background(), tri); err != nil {
+			t.Fatalf("failed to insert '%s': %v", tri, err)
+		}
+	}
+}
+
+func assertTriples(t *testing.T, fab *fabric.Fabric, expected []fabric.Triple) {
+	triples, err := fab.Query(context.Background(), fabric.Query{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, triples) {
+		t.Errorf("expected %v, got %v", expected, triples)
+	}
+}
+
+// storeOnly hides all the optional interfaces implemented by the store.
+type storeOnly struct {
+	fabric.Store
+}
@@ -1 +1,338 @@
 package fabric
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FQL (Fabric Query Language) is a small textual language for querying the
+// fabric. A statement is one or more triple patterns separated by '.' and
+// optionally followed by a WHERE and a LIMIT section:
+//
+//	query   := pattern ('.' pattern)* [WHERE cond (AND cond)*] [LIMIT number]
+//	pattern := term term term
+//	term    := name | "quoted name" | ?variable
+//	cond    := (weight | ?variable) op value
+//	op      := = | == | != | > | >= | < | <= | ~ | !~
+//
+// For example:
+//
+//	Bob knows ?x . ?x works_at ?y WHERE weight > 0.5 LIMIT 10
+//
+// Variables bind across patterns. Conditions on weight apply to every pattern
+// and conditions on variables filter the resulting bindings. Keywords are
+// case-insensitive; names that collide with keywords must be quoted.
+
+// FQLQuery is the parsed form of an FQL statement.
+type FQLQuery struct {
+	Patterns []Pattern
+	Filters  []FQLFilter
+	Limit    int
+}
+
+// FQLFilter is a condition on the value bound to a variable.
+type FQLFilter struct {
+	Variable string
+	Clause   Clause
+}
+
+// ParseFQL parses the given FQL statement.
+func ParseFQL(src string) (*FQLQuery, error) {
+	tokens, err := lexFQL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &fqlParser{tokens: tokens}
+	return p.parse()
+}
+
+// Execute runs the query against the given store and returns the variable
+// bindings of all the matching rows. Patterns are joined as described in
+// Fabric.Match.
+func (fq FQLQuery) Execute(ctx context.Context, store Store) ([]Binding, error) {
+	filters := make([]stringMatcher, len(fq.Filters))
+	for i, filter := range fq.Filters {
+		m, err := compileClause(filter.Clause)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = m
+	}
+
+	res := []Binding{}
+	err := New(store).match(ctx, fq.Patterns, func(b Binding) (bool, error) {
+		for i, filter := range fq.Filters {
+			if !filters[i](b[filter.Variable]) {
+				return true, nil
+			}
+		}
+
+		res = append(res, b)
+		return fq.Limit <= 0 || len(res) < fq.Limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// QueryFQL parses and executes the given FQL statement.
+func (f *Fabric) QueryFQL(ctx context.Context, src string) ([]Binding, error) {
+	fq, err := ParseFQL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return fq.Execute(ctx, f)
+}
+
+type fqlParser struct {
+	tokens []fqlToken
+	pos    int
+}
+
+func (p *fqlParser) parse() (*FQLQuery, error) {
+	fq := &FQLQuery{}
+
+	for {
+		pat, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		fq.Patterns = append(fq.Patterns, *pat)
+
+		if p.peek().kind != tokDot {
+			break
+		}
+		p.next()
+
+		// a trailing '.' after the last pattern is allowed.
+		if tok := p.peek(); tok.kind == tokEOF || (tok.kind == tokName && isFQLKeyword(tok.text)) {
+			break
+		}
+	}
+
+	if p.peekKeyword("WHERE") {
+		p.next()
+		if err := p.parseConditions(fq); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peekKeyword("LIMIT") {
+		p.next()
+		tok := p.next()
+		limit, err := strconv.Atoi(tok.text)
+		if tok.kind != tokName || err != nil || limit < 0 {
+			return nil, p.errorf(tok, "expecting a non-negative limit")
+		}
+		fq.Limit = limit
+	}
+
+	if tok := p.next(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected '%s'", tok.text)
+	}
+
+	return fq, nil
+}
+
+func (p *fqlParser) parsePattern() (*Pattern, error) {
+	var terms [3]string
+	for i := range terms {
+		tok := p.next()
+		switch {
+		case tok.kind == tokVar, tok.kind == tokString:
+			terms[i] = tok.text
+
+		case tok.kind == tokName && !isFQLKeyword(tok.text):
+			terms[i] = tok.text
+
+		default:
+			return nil, p.errorf(tok, "expecting a name or variable, got '%s'", tok.text)
+		}
+	}
+
+	return &Pattern{Source: terms[0], Predicate: terms[1], Target: terms[2]}, nil
+}
+
+func (p *fqlParser) parseConditions(fq *FQLQuery) error {
+	for {
+		field := p.next()
+		op := p.next()
+		val := p.next()
+
+		if op.kind != tokOp {
+			return p.errorf(op, "expecting an operator, got '%s'", op.text)
+		}
+
+		if val.kind != tokName && val.kind != tokString {
+			return p.errorf(val, "expecting a value, got '%s'", val.text)
+		}
+
+		cl := Clause{Type: op.text, Value: val.text}
+		cl.normalize()
+
+		switch {
+		case field.kind == tokVar:
+			name, _ := variableName(field.text)
+			fq.Filters = append(fq.Filters, FQLFilter{Variable: name, Clause: cl})
+
+		case field.kind == tokName && strings.EqualFold(field.text, "weight"):
+			if _, err := strconv.ParseFloat(val.text, 64); err != nil {
+				return p.errorf(val, "weight must be a number, got '%s'", val.text)
+			}
+
+			for i := range fq.Patterns {
+				if !fq.Patterns[i].Weight.IsAny() {
+					return p.errorf(field, "duplicate weight condition")
+				}
+				fq.Patterns[i].Weight = cl
+			}
+
+		default:
+			return p.errorf(field, "expecting 'weight' or a variable, got '%s'", field.text)
+		}
+
+		if !p.peekKeyword("AND") {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *fqlParser) peek() fqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *fqlParser) peekKeyword(kw string) bool {
+	tok := p.peek()
+	return tok.kind == tokName && strings.EqualFold(tok.text, kw)
+}
+
+func (p *fqlParser) next() fqlToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *fqlParser) errorf(tok fqlToken, format string, args ...interface{}) error {
+	return fmt.Errorf("fql: offset %d: %s", tok.pos, fmt.Sprintf(format, args...))
+}
+
+func isFQLKeyword(s string) bool {
+	switch strings.ToUpper(s) {
+	case "WHERE", "LIMIT", "AND":
+		return true
+	}
+	return false
+}
+
+const (
+	tokEOF = iota
+	tokName
+	tokVar
+	tokString
+	tokOp
+	tokDot
+)
+
+type fqlToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+func lexFQL(src string) ([]fqlToken, error) {
+	var tokens []fqlToken
+	rs := []rune(src)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '.' && (i+1 == len(rs) || unicode.IsSpace(rs[i+1])):
+			tokens = append(tokens, fqlToken{kind: tokDot, text: ".", pos: i})
+			i++
+
+		case r == '"':
+			end := i + 1
+			for ; end < len(rs) && rs[end] != '"'; end++ {
+				if rs[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("fql: offset %d: unterminated string", i)
+			}
+
+			s, err := strconv.Unquote(string(rs[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("fql: offset %d: invalid string: %v", i, err)
+			}
+			tokens = append(tokens, fqlToken{kind: tokString, text: s, pos: i})
+			i = end + 1
+
+		case r == '?':
+			end := i + 1
+			for end < len(rs) && (unicode.IsLetter(rs[end]) || unicode.IsDigit(rs[end]) || rs[end] == '_') {
+				end++
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("fql: offset %d: empty variable name", i)
+			}
+			tokens = append(tokens, fqlToken{kind: tokVar, text: string(rs[i:end]), pos: i})
+			i = end
+
+		case strings.ContainsRune(fqlOpChars, r):
+			end := i + 1
+			for end < len(rs) && strings.ContainsRune(fqlOpChars, rs[end]) {
+				end++
+			}
+			op := string(rs[i:end])
+			if _, ok := fqlOperators[op]; !ok {
+				return nil, fmt.Errorf("fql: offset %d: unknown operator '%s'", i, op)
+			}
+			tokens = append(tokens, fqlToken{kind: tokOp, text: op, pos: i})
+			i = end
+
+		case strings.ContainsRune(forbiddenChars, r):
+			return nil, fmt.Errorf("fql: offset %d: unexpected '%c'", i, r)
+
+		default:
+			end := i
+			for end < len(rs) && isFQLNameRune(rs, end) {
+				end++
+			}
+			tokens = append(tokens, fqlToken{kind: tokName, text: string(rs[i:end]), pos: i})
+			i = end
+		}
+	}
+
+	return append(tokens, fqlToken{kind: tokEOF, text: "<eof>", pos: len(rs)}), nil
+}
+
+func isFQLNameRune(rs []rune, i int) bool {
+	r := rs[i]
+	if unicode.IsSpace(r) || r == '"' || strings.ContainsRune(forbiddenChars+fqlOpChars, r) {
+		return false
+	}
+
+	// a '.' followed by whitespace terminates a pattern and is not part of
+	// the name.
+	return r != '.' || (i+1 < len(rs) && !unicode.IsSpace(rs[i+1]))
+}
+
+const fqlOpChars = "=!<>~"
+
+var fqlOperators = map[string]struct{}{
+	"=": {}, "==": {}, "!=": {}, ">": {}, ">=": {}, "<": {}, "<=": {}, "~": {}, "~=": {}, "!~": {},
+}
@@ -0,0 +1,161 @@
+package fabric_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestParseFQL(suite *testing.T) {
+	suite.Parallel()
+
+	cases := []struct {
+		title     string
+		src       string
+		expected  *fabric.FQLQuery
+		expectErr bool
+	}{
+		{
+			title: "SinglePattern",
+			src:   "Bob knows ?x",
+			expected: &fabric.FQLQuery{
+				Patterns: []fabric.Pattern{
+					{Source: "Bob", Predicate: "knows", Target: "?x"},
+				},
+			},
+		},
+		{
+			title: "MultiplePatternsWithWhereAndLimit",
+			src:   `Bob knows ?x . ?x works_at ?y WHERE weight > 0.5 AND ?y ~ "Acme*" limit 10`,
+			expected: &fabric.FQLQuery{
+				Patterns: []fabric.Pattern{
+					{Source: "Bob", Predicate: "knows", Target: "?x", Weight: fabric.Clause{Type: "gt", Value: "0.5"}},
+					{Source: "?x", Predicate: "works_at", Target: "?y", Weight: fabric.Clause{Type: "gt", Value: "0.5"}},
+				},
+				Filters: []fabric.FQLFilter{
+					{Variable: "y", Clause: fabric.Clause{Type: "like", Value: "Acme*"}},
+				},
+				Limit: 10,
+			},
+		},
+		{
+			title: "QuotedNamesAndDottedNames",
+			src:   `"Bob Smith" knows example.com.`,
+			expected: &fabric.FQLQuery{
+				Patterns: []fabric.Pattern{
+					{Source: "Bob Smith", Predicate: "knows", Target: "example.com"},
+				},
+			},
+		},
+		{
+			title:     "IncompletePattern",
+			src:       "Bob knows",
+			expectErr: true,
+		},
+		{
+			title:     "InvalidWeight",
+			src:       "Bob knows ?x WHERE weight > high",
+			expectErr: true,
+		},
+		{
+			title:     "InvalidLimit",
+			src:       "Bob knows ?x LIMIT -1",
+			expectErr: true,
+		},
+		{
+			title:     "UnterminatedString",
+			src:       `Bob knows "John`,
+			expectErr: true,
+		},
+		{
+			title:     "TrailingTokens",
+			src:       "Bob knows John Doe",
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			fq, err := fabric.ParseFQL(cs.src)
+			if err != nil {
+				if !cs.expectErr {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if cs.expectErr {
+				t.Fatalf("expecting error, got nil")
+			}
+
+			if !reflect.DeepEqual(cs.expected, fq) {
+				t.Errorf("expected %+v, got %+v", cs.expected, fq)
+			}
+		})
+	}
+}
+
+func TestFabric_QueryFQL(suite *testing.T) {
+	suite.Parallel()
+
+	fab := fabric.New(&fabric.InMemoryStore{})
+	for _, tri := range []fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1},
+		{Source: "Bob", Predicate: "knows", Target: "Alice", Weight: 0.2},
+		{Source: "John", Predicate: "works_at", Target: "Acme"},
+		{Source: "Alice", Predicate: "works_at", Target: "Globex"},
+	} {
+		if err := fab.Insert(context.Background(), tri); err != nil {
+			suite.Fatalf("failed to insert: %v", err)
+		}
+	}
+
+	suite.Run("Join", func(t *testing.T) {
+		res, err := fab.QueryFQL(context.Background(), "Bob knows ?x . ?x works_at ?y WHERE ?y ~ Acme")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []fabric.Binding{{"x": "John", "y": "Acme"}}
+		if !reflect.DeepEqual(expected, res) {
+			t.Errorf("expected %v, got %v", expected, res)
+		}
+	})
+
+	suite.Run("WeightCondition", func(t *testing.T) {
+		res, err := fab.QueryFQL(context.Background(), "Bob knows ?x WHERE weight < 0.5")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []fabric.Binding{{"x": "Alice"}}
+		if !reflect.DeepEqual(expected, res) {
+			t.Errorf("expected %v, got %v", expected, res)
+		}
+	})
+
+	suite.Run("NotEqual", func(t *testing.T) {
+		res, err := fab.QueryFQL(context.Background(), "Bob knows ?x WHERE ?x != John")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []fabric.Binding{{"x": "Alice"}}
+		if !reflect.DeepEqual(expected, res) {
+			t.Errorf("expected %v, got %v", expected, res)
+		}
+	})
+
+	suite.Run("Limit", func(t *testing.T) {
+		res, err := fab.QueryFQL(context.Background(), "?a ?p ?b LIMIT 3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(res) != 3 {
+			t.Errorf("expected 3 bindings, got %d", len(res))
+		}
+	})
+}
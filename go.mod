@@ -1,5 +1,5 @@
 module github.com/spy16/fabric
 
-go 1.12
+go 1.14
 
 require github.com/mattn/go-sqlite3 v1.9.0
@@ -0,0 +1,245 @@
+package fabric
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// memIndex maintains the secondary indexes of an InMemoryStore: a hash index
+// for each of source, predicate and target and a sorted index on weight.
+// Indexes map to triple ids as generated by InMemoryStore.idFor.
+type memIndex struct {
+	source    hashIndex
+	predicate hashIndex
+	target    hashIndex
+	weight    *weightIndex
+}
+
+func newMemIndex() *memIndex {
+	return &memIndex{
+		source:    hashIndex{},
+		predicate: hashIndex{},
+		target:    hashIndex{},
+		weight:    newWeightIndex(),
+	}
+}
+
+func (idx *memIndex) add(id string, tri Triple) {
+	idx.source.add(tri.Source, id)
+	idx.predicate.add(tri.Predicate, id)
+	idx.target.add(tri.Target, id)
+	idx.weight.add(tri.Weight, id)
+}
+
+func (idx *memIndex) remove(id string, tri Triple) {
+	idx.source.remove(tri.Source, id)
+	idx.predicate.remove(tri.Predicate, id)
+	idx.target.remove(tri.Target, id)
+	idx.weight.remove(tri.Weight, id)
+}
+
+// plan picks the most selective index for the query and returns a function
+// that iterates over the ids of the candidate triples. Candidates still need
+// to be matched against the query. Returns nil if no index is applicable and
+// a full scan is required.
+func (idx *memIndex) plan(query Query) func(fn func(id string) bool) {
+	var best map[string]struct{}
+	found := false
+
+	hashes := []struct {
+		index  hashIndex
+		clause Clause
+	}{
+		{idx.source, query.Source},
+		{idx.predicate, query.Predicate},
+		{idx.target, query.Target},
+	}
+	for _, h := range hashes {
+		if !isEqClause(h.clause) {
+			continue
+		}
+
+		ids := h.index[h.clause.Value]
+		if !found || len(ids) < len(best) {
+			best, found = ids, true
+		}
+	}
+
+	if found {
+		return func(fn func(id string) bool) {
+			for id := range best {
+				if !fn(id) {
+					return
+				}
+			}
+		}
+	}
+
+	if scan := idx.weight.rangeScan(query.Weight); scan != nil {
+		return scan
+	}
+
+	return nil
+}
+
+func isEqClause(cl Clause) bool {
+	switch cl.Type {
+	case "eq", "=", "==", "equal":
+		return true
+	}
+	return false
+}
+
+// hashIndex maps a value to the set of ids of triples having that value.
+type hashIndex map[string]map[string]struct{}
+
+func (h hashIndex) add(value, id string) {
+	ids, found := h[value]
+	if !found {
+		ids = map[string]struct{}{}
+		h[value] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func (h hashIndex) remove(value, id string) {
+	ids := h[value]
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(h, value)
+	}
+}
+
+const weightIndexMaxLevel = 32
+
+// weightIndex is a skip list of (weight, id) pairs in ascending order which
+// supports range scans on weight.
+type weightIndex struct {
+	head  *weightNode
+	level int
+	rnd   *rand.Rand
+}
+
+type weightNode struct {
+	weight float64
+	id     string
+	next   []*weightNode
+}
+
+func newWeightIndex() *weightIndex {
+	return &weightIndex{
+		head:  &weightNode{next: make([]*weightNode, weightIndexMaxLevel)},
+		level: 1,
+		rnd:   rand.New(rand.NewSource(1)),
+	}
+}
+
+func (wi *weightIndex) add(weight float64, id string) {
+	var update [weightIndexMaxLevel]*weightNode
+	node := wi.head
+	for lvl := wi.level - 1; lvl >= 0; lvl-- {
+		for node.next[lvl] != nil && weightLess(node.next[lvl], weight, id) {
+			node = node.next[lvl]
+		}
+		update[lvl] = node
+	}
+
+	level := 1
+	for level < weightIndexMaxLevel && wi.rnd.Intn(4) == 0 {
+		level++
+	}
+
+	if level > wi.level {
+		for lvl := wi.level; lvl < level; lvl++ {
+			update[lvl] = wi.head
+		}
+		wi.level = level
+	}
+
+	n := &weightNode{weight: weight, id: id, next: make([]*weightNode, level)}
+	for lvl := 0; lvl < level; lvl++ {
+		n.next[lvl] = update[lvl].next[lvl]
+		update[lvl].next[lvl] = n
+	}
+}
+
+func (wi *weightIndex) remove(weight float64, id string) {
+	node := wi.head
+	for lvl := wi.level - 1; lvl >= 0; lvl-- {
+		for node.next[lvl] != nil && weightLess(node.next[lvl], weight, id) {
+			node = node.next[lvl]
+		}
+
+		if n := node.next[lvl]; n != nil && n.weight == weight && n.id == id {
+			node.next[lvl] = n.next[lvl]
+		}
+	}
+
+	for wi.level > 1 && wi.head.next[wi.level-1] == nil {
+		wi.level--
+	}
+}
+
+// seek returns the first node with weight >= w (or > w if strict).
+func (wi *weightIndex) seek(w float64, strict bool) *weightNode {
+	node := wi.head
+	for lvl := wi.level - 1; lvl >= 0; lvl-- {
+		for n := node.next[lvl]; n != nil && (n.weight < w || (strict && n.weight == w)); n = node.next[lvl] {
+			node = n
+		}
+	}
+	return node.next[0]
+}
+
+// rangeScan returns a function iterating over the ids of triples whose
+// weight satisfies the clause. Returns nil if the clause cannot be served
+// by the index.
+func (wi *weightIndex) rangeScan(cl Clause) func(fn func(id string) bool) {
+	if cl.IsAny() {
+		return nil
+	}
+
+	w, err := strconv.ParseFloat(cl.Value, 64)
+	if err != nil {
+		return nil
+	}
+
+	var start *weightNode
+	var inRange func(n *weightNode) bool
+	switch cl.Type {
+	case "eq", "=", "==", "equal":
+		start = wi.seek(w, false)
+		inRange = func(n *weightNode) bool { return n.weight == w }
+
+	case "gt", ">":
+		start = wi.seek(w, true)
+		inRange = func(n *weightNode) bool { return true }
+
+	case "gte", ">=":
+		start = wi.seek(w, false)
+		inRange = func(n *weightNode) bool { return true }
+
+	case "lt", "<":
+		start = wi.head.next[0]
+		inRange = func(n *weightNode) bool { return n.weight < w }
+
+	case "lte", "<=":
+		start = wi.head.next[0]
+		inRange = func(n *weightNode) bool { return n.weight <= w }
+
+	default:
+		return nil
+	}
+
+	return func(fn func(id string) bool) {
+		for n := start; n != nil && inRange(n); n = n.next[0] {
+			if !fn(n.id) {
+				return
+			}
+		}
+	}
+}
+
+func weightLess(n *weightNode, weight float64, id string) bool {
+	return n.weight < weight || (n.weight == weight && n.id < id)
+}
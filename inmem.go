@@ -2,37 +2,35 @@ package fabric
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
-	"strings"
 	"sync"
 )
 
 var _ Store = &InMemoryStore{}
 var _ ReWeighter = &InMemoryStore{}
 var _ Counter = &InMemoryStore{}
+var _ Transactor = &InMemoryStore{}
+var _ BulkInserter = &InMemoryStore{}
+var _ Upserter = &InMemoryStore{}
+var _ Returner = &InMemoryStore{}
 
 // InMemoryStore implements the Store interface using the golang
-// map type.
+// map type. Queries are served using secondary indexes on source,
+// predicate, target and weight whenever possible.
 type InMemoryStore struct {
-	mu   *sync.RWMutex
-	data map[string]Triple
+	mu    *sync.RWMutex
+	data  map[string]Triple
+	index *memIndex
 }
 
 // Count returns the number of triples in the store matching the given query.
 func (mem *InMemoryStore) Count(ctx context.Context, query Query) (int, error) {
-	if query.IsAny() {
-		return len(mem.data), nil
-	}
+	mem.ensureInit()
 
-	triples, err := mem.Query(ctx, query)
-	if err != nil {
-		return 0, err
-	}
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
 
-	return len(triples), nil
+	return mem.count(query)
 }
 
 // Insert stores the triple into the in-memory map.
@@ -42,16 +40,36 @@ func (mem *InMemoryStore) Insert(ctx context.Context, tri Triple) error {
 	mem.mu.Lock()
 	defer mem.mu.Unlock()
 
-	if mem.data == nil {
-		mem.data = map[string]Triple{}
-	}
+	return mem.insert(tri)
+}
 
-	if _, ok := mem.data[mem.idFor(tri)]; ok {
-		return errors.New("triple already exists")
+// InsertMany stores all the triples into the in-memory map while holding
+// the lock only once.
+func (mem *InMemoryStore) InsertMany(ctx context.Context, triples []Triple) ([]BatchError, error) {
+	mem.ensureInit()
+
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	var failed []BatchError
+	for i, tri := range triples {
+		if err := mem.insert(tri); err != nil {
+			failed = append(failed, BatchError{Index: i, Err: err})
+		}
 	}
 
-	mem.data[mem.idFor(tri)] = tri
+	return failed, nil
+}
+
+// Upsert stores the triple into the in-memory map, resolving a conflict with
+// an existing triple using the given policy.
+func (mem *InMemoryStore) Upsert(ctx context.Context, tri Triple, policy ConflictPolicy) error {
+	mem.ensureInit()
+
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
 
+	mem.upsert(tri, policy)
 	return nil
 }
 
@@ -62,170 +80,387 @@ func (mem *InMemoryStore) Query(ctx context.Context, query Query) ([]Triple, err
 	mem.mu.RLock()
 	defer mem.mu.RUnlock()
 
-	triples := []Triple{}
-	for _, tri := range mem.data {
-		if query.Limit > 0 && len(triples) >= query.Limit {
-			break
-		}
+	return mem.query(query)
+}
 
-		if query.IsAny() {
-			triples = append(triples, tri)
-			continue
-		}
+// Iterate calls fn for every triple matching the query. The lock is released
+// before fn is called so that fn can make changes to the store.
+func (mem *InMemoryStore) Iterate(ctx context.Context, query Query, fn func(tri Triple) error) error {
+	triples, err := mem.Query(ctx, query)
+	if err != nil {
+		return err
+	}
 
-		match, err := isMatch(tri, query)
-		if err != nil {
-			return nil, err
+	for _, tri := range triples {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		if match {
-			triples = append(triples, tri)
+		if err := fn(tri); err != nil {
+			return err
 		}
-
 	}
-
-	return triples, nil
+	return nil
 }
 
 // Delete removes all the triples that match the given query.
 func (mem *InMemoryStore) Delete(ctx context.Context, query Query) (int, error) {
-	if mem.data == nil {
-		return 0, nil
-	}
+	triples, err := mem.DeleteReturning(ctx, query)
+	return len(triples), err
+}
 
-	triples, err := mem.Query(ctx, query)
-	if err != nil {
-		return 0, err
-	}
+// DeleteReturning removes all the triples that match the given query and
+// returns them.
+func (mem *InMemoryStore) DeleteReturning(ctx context.Context, query Query) ([]Triple, error) {
+	mem.ensureInit()
 
 	mem.mu.Lock()
 	defer mem.mu.Unlock()
 
+	if query.IsAny() {
+		return nil, errNoClause
+	}
+
+	triples, err := mem.matching(query)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, tri := range triples {
-		delete(mem.data, mem.idFor(tri))
+		mem.remove(tri)
 	}
 
-	return len(triples), nil
+	return triples, nil
 }
 
 // ReWeight re-weights all the triples matching the query.
 func (mem *InMemoryStore) ReWeight(ctx context.Context, query Query, delta float64, replace bool) (int, error) {
-	triples, err := mem.Query(ctx, query)
+	triples, err := mem.ReWeightReturning(ctx, query, delta, replace)
+	return len(triples), err
+}
+
+// ReWeightReturning re-weights all the triples matching the query and
+// returns the updated triples.
+func (mem *InMemoryStore) ReWeightReturning(ctx context.Context, query Query, delta float64, replace bool) ([]Triple, error) {
+	mem.ensureInit()
+
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	triples, err := mem.matching(query)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
+	for i, tri := range triples {
+		triples[i] = reweighted(tri, delta, replace)
+		mem.put(triples[i])
+	}
+
+	return triples, nil
+}
+
+// Begin starts a new transaction. The transaction holds the write lock on
+// the store until it is committed or rolled back.
+func (mem *InMemoryStore) Begin(ctx context.Context) (Tx, error) {
+	mem.ensureInit()
+
 	mem.mu.Lock()
-	defer mem.mu.Unlock()
+	return &memTx{mem: mem}, nil
+}
 
-	for _, tri := range triples {
-		if replace {
-			tri.Weight = delta
-		} else {
-			tri.Weight += delta
-		}
+// count, insert, query, matching, remove and put expect the caller to hold
+// the lock.
 
-		mem.data[mem.idFor(tri)] = tri
+func (mem *InMemoryStore) count(query Query) (int, error) {
+	if query.IsAny() {
+		return len(mem.data), nil
 	}
 
-	return len(triples), nil
+	count := 0
+	err := mem.scan(query, func(tri Triple) { count++ })
+	return count, err
 }
 
-func (mem *InMemoryStore) ensureInit() {
-	if mem.mu == nil {
-		mem.mu = new(sync.RWMutex)
+func (mem *InMemoryStore) insert(tri Triple) error {
+	if _, ok := mem.data[mem.idFor(tri)]; ok {
+		return ErrConflict
 	}
+
+	mem.put(tri)
+	return nil
 }
 
-func (mem *InMemoryStore) idFor(tri Triple) string {
-	return fmt.Sprintf("%s %s %s", tri.Source, tri.Predicate, tri.Target)
+// upsert returns the triple that was replaced if any.
+func (mem *InMemoryStore) upsert(tri Triple, policy ConflictPolicy) (Triple, bool) {
+	existing, found := mem.data[mem.idFor(tri)]
+	if found {
+		tri.Weight = policy.resolve(existing.Weight, tri.Weight)
+		if policy == KeepExisting || len(tri.Properties) == 0 {
+			tri.Properties = existing.Properties
+		}
+	}
+
+	mem.put(tri)
+	return existing, found
 }
 
-func isMatch(tri Triple, query Query) (bool, error) {
-	matchers := []matcher{
-		matchClause(tri.Source, query.Source),
-		matchClause(tri.Predicate, query.Predicate),
-		matchClause(tri.Target, query.Target),
+func (mem *InMemoryStore) query(query Query) ([]Triple, error) {
+	keys, err := query.sortKeys()
+	if err != nil {
+		return nil, err
 	}
 
-	for _, matcher := range matchers {
-		match, err := matcher()
-		if err != nil {
-			return false, err
-		}
+	last, err := query.after(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the first offset+limit triples after the cursor are required when
+	// there is a limit.
+	k := 0
+	if query.Limit > 0 {
+		k = query.skip() + query.Limit
+	}
 
-		if !match {
-			return false, nil
+	first := newFirstTriples(k, keys)
+	err = mem.scan(query, func(tri Triple) {
+		if last == nil || compareTriples(tri, *last, keys) > 0 {
+			first.add(tri)
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	match, err := isWeightMatch(tri.Weight, query.Weight)
+	return paginate(first.sorted(), query, keys)
+}
+
+// matching returns all the triples matching the query in no particular order
+// ignoring the Limit, Offset and Cursor of the query.
+func (mem *InMemoryStore) matching(query Query) ([]Triple, error) {
+	triples := []Triple{}
+	err := mem.scan(query, func(tri Triple) {
+		triples = append(triples, tri)
+	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return match, nil
+	return triples, nil
 }
 
-func matchClause(actual string, clause Clause) matcher {
-	if clause.IsAny() {
-		return func() (bool, error) {
-			return true, nil
+// scan calls fn for every triple matching the query in no particular order
+// ignoring the Limit, Offset, Cursor and the order of the query.
+func (mem *InMemoryStore) scan(query Query, fn func(tri Triple)) error {
+	if query.IsAny() {
+		for _, tri := range mem.data {
+			fn(tri)
 		}
+		return nil
 	}
 
-	switch clause.Type {
-	case "=", "==", "equal":
-		return func() (bool, error) {
-			return clause.Value == actual, nil
-		}
+	m, err := compileQuery(query)
+	if err != nil {
+		return err
+	}
 
-	case "~", "~=", "like":
-		return func() (bool, error) {
-			exp := strings.Replace(clause.Value, "*", ".*", -1)
-			re, err := regexp.Compile(exp)
-			if err != nil {
-				return false, err
+	if scan := mem.plan(query); scan != nil {
+		scan(func(id string) bool {
+			if tri := mem.data[id]; m.match(tri) {
+				fn(tri)
 			}
+			return true
+		})
+		return nil
+	}
 
-			return re.MatchString(actual), nil
+	for _, tri := range mem.data {
+		if m.match(tri) {
+			fn(tri)
 		}
+	}
+	return nil
+}
+
+// plan returns a function iterating over the ids of the candidate triples
+// for the query using the most selective index. Returns nil if a full scan
+// is required.
+func (mem *InMemoryStore) plan(query Query) func(fn func(id string) bool) {
+	if mem.index == nil {
+		return nil
+	}
+	return mem.index.plan(query)
+}
+
+func (mem *InMemoryStore) remove(tri Triple) {
+	id := mem.idFor(tri)
+	if existing, found := mem.data[id]; found {
+		mem.index.remove(id, existing)
+		delete(mem.data, id)
+	}
+}
+
+func (mem *InMemoryStore) put(tri Triple) {
+	if mem.data == nil {
+		mem.data = map[string]Triple{}
+		mem.index = newMemIndex()
+	}
+
+	id := mem.idFor(tri)
+	if existing, found := mem.data[id]; found {
+		mem.index.remove(id, existing)
+	}
+
+	mem.data[id] = tri
+	mem.index.add(id, tri)
+}
+
+func (mem *InMemoryStore) ensureInit() {
+	if mem.mu == nil {
+		mem.mu = new(sync.RWMutex)
+	}
+}
+
+func (mem *InMemoryStore) idFor(tri Triple) string {
+	return fmt.Sprintf("%s %s %s", tri.Source, tri.Predicate, tri.Target)
+}
+
+func reweighted(tri Triple, delta float64, replace bool) Triple {
+	if replace {
+		tri.Weight = delta
+	} else {
+		tri.Weight += delta
+	}
+	return tri
+}
 
+// memTx is an InMemoryStore transaction. Changes are applied to the store
+// directly and an undo log is maintained to revert them on rollback.
+type memTx struct {
+	mem  *InMemoryStore
+	undo []func()
+	done bool
+}
+
+func (tx *memTx) Insert(ctx context.Context, tri Triple) error {
+	if tx.done {
+		return ErrTxDone
+	}
+
+	if err := tx.mem.insert(tri); err != nil {
+		return err
+	}
+
+	tx.undo = append(tx.undo, func() { tx.mem.remove(tri) })
+	return nil
+}
+
+func (tx *memTx) Upsert(ctx context.Context, tri Triple, policy ConflictPolicy) error {
+	if tx.done {
+		return ErrTxDone
+	}
+
+	existing, found := tx.mem.upsert(tri, policy)
+	if found {
+		tx.undo = append(tx.undo, func() { tx.mem.put(existing) })
+	} else {
+		tx.undo = append(tx.undo, func() { tx.mem.remove(tri) })
 	}
+	return nil
+}
 
-	return func() (bool, error) {
-		return false, fmt.Errorf("clause type '%s' not supported", clause.Type)
+func (tx *memTx) Query(ctx context.Context, query Query) ([]Triple, error) {
+	if tx.done {
+		return nil, ErrTxDone
 	}
+
+	return tx.mem.query(query)
 }
 
-func isWeightMatch(actual float64, clause Clause) (bool, error) {
-	if clause.IsAny() {
-		return true, nil
+func (tx *memTx) Count(ctx context.Context, query Query) (int, error) {
+	if tx.done {
+		return 0, ErrTxDone
+	}
+
+	return tx.mem.count(query)
+}
+
+func (tx *memTx) Delete(ctx context.Context, query Query) (int, error) {
+	triples, err := tx.DeleteReturning(ctx, query)
+	return len(triples), err
+}
+
+func (tx *memTx) DeleteReturning(ctx context.Context, query Query) ([]Triple, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+
+	if query.IsAny() {
+		return nil, errNoClause
 	}
 
-	w, err := strconv.ParseFloat(clause.Value, 64)
+	triples, err := tx.mem.matching(query)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	for _, tri := range triples {
+		tri := tri
+		tx.mem.remove(tri)
+		tx.undo = append(tx.undo, func() { tx.mem.put(tri) })
 	}
 
-	switch clause.Type {
-	case "=", "==", "equal":
-		return actual == w, nil
+	return triples, nil
+}
 
-	case ">=", "gte":
-		return actual >= w, nil
+func (tx *memTx) ReWeight(ctx context.Context, query Query, delta float64, replace bool) (int, error) {
+	triples, err := tx.ReWeightReturning(ctx, query, delta, replace)
+	return len(triples), err
+}
+
+func (tx *memTx) ReWeightReturning(ctx context.Context, query Query, delta float64, replace bool) ([]Triple, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+
+	triples, err := tx.mem.matching(query)
+	if err != nil {
+		return nil, err
+	}
 
-	case "<=", "lte":
-		return actual <= w, nil
+	for i, tri := range triples {
+		tri := tri
+		triples[i] = reweighted(tri, delta, replace)
+		tx.mem.put(triples[i])
+		tx.undo = append(tx.undo, func() { tx.mem.put(tri) })
+	}
 
-	case ">", "gt":
-		return actual > w, nil
+	return triples, nil
+}
 
-	case "<", "lt":
-		return actual < w, nil
+func (tx *memTx) Commit() error {
+	if tx.done {
+		return ErrTxDone
 	}
 
-	return false, nil
+	tx.done = true
+	tx.undo = nil
+	tx.mem.mu.Unlock()
+	return nil
 }
 
-type matcher func() (bool, error)
+func (tx *memTx) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+
+	tx.done = true
+	tx.undo = nil
+	tx.mem.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,225 @@
+package fabric_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestInMemoryStore_Indexes(suite *testing.T) {
+	suite.Parallel()
+
+	rnd := rand.New(rand.NewSource(42))
+	store := &fabric.InMemoryStore{}
+	ctx := context.Background()
+
+	var all []fabric.Triple
+	for i := 0; i < 2000; i++ {
+		tri := fabric.Triple{
+			Source:    fmt.Sprintf("s%d", rnd.Intn(50)),
+			Predicate: fmt.Sprintf("p%d", rnd.Intn(5)),
+			Target:    fmt.Sprintf("t%d", rnd.Intn(100)),
+			Weight:    float64(rnd.Intn(20)),
+		}
+		if store.Insert(ctx, tri) == nil {
+			all = append(all, tri)
+		}
+	}
+
+	// changes to weights and deletions must be reflected in the indexes.
+	if _, err := store.ReWeight(ctx, fabric.Query{Predicate: fabric.Clause{Type: "eq", Value: "p1"}}, 100, false); err != nil {
+		suite.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Delete(ctx, fabric.Query{Source: fabric.Clause{Type: "eq", Value: "s7"}}); err != nil {
+		suite.Fatalf("unexpected error: %v", err)
+	}
+
+	var current []fabric.Triple
+	for _, tri := range all {
+		if tri.Source == "s7" {
+			continue
+		}
+		if tri.Predicate == "p1" {
+			tri.Weight += 100
+		}
+		current = append(current, tri)
+	}
+
+	queries := []fabric.Query{
+		{Source: fabric.Clause{Type: "eq", Value: "s3"}},
+		{Source: fabric.Clause{Type: "eq", Value: "s7"}},
+		{Source: fabric.Clause{Type: "eq", Value: "s3"}, Predicate: fabric.Clause{Type: "eq", Value: "p1"}},
+		{Predicate: fabric.Clause{Type: "eq", Value: "p2"}, Target: fabric.Clause{Type: "eq", Value: "t9"}},
+		{Target: fabric.Clause{Type: "like", Value: "t1*"}, Predicate: fabric.Clause{Type: "eq", Value: "p0"}},
+		{Weight: fabric.Clause{Type: "eq", Value: "5"}},
+		{Weight: fabric.Clause{Type: "gt", Value: "100"}},
+		{Weight: fabric.Clause{Type: "gte", Value: "18"}},
+		{Weight: fabric.Clause{Type: "lt", Value: "2"}},
+		{Weight: fabric.Clause{Type: "lte", Value: "1"}, Source: fabric.Clause{Type: "eq", Value: "s1"}},
+	}
+
+	for _, q := range queries {
+		suite.Run(fmt.Sprintf("%v", q.Map()), func(t *testing.T) {
+			got, err := store.Query(ctx, q)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var expected []fabric.Triple
+			for _, tri := range current {
+				if bruteForceMatch(tri, q) {
+					expected = append(expected, tri)
+				}
+			}
+
+			sortTriples(got)
+			sortTriples(expected)
+			if fmt.Sprint(expected) != fmt.Sprint(got) {
+				t.Errorf("expected %d triples, got %d", len(expected), len(got))
+			}
+		})
+	}
+}
+
+func TestInMemoryStore_QueryLimit(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	store := &fabric.InMemoryStore{}
+	ctx := context.Background()
+
+	for i := 0; i < 1000; i++ {
+		store.Insert(ctx, fabric.Triple{
+			Source:    fmt.Sprintf("s%d", rnd.Intn(50)),
+			Predicate: "p",
+			Target:    fmt.Sprintf("t%d", rnd.Intn(100)),
+			Weight:    float64(rnd.Intn(20)),
+		})
+	}
+
+	orders := [][]fabric.Order{
+		nil,
+		{{Field: "weight", Desc: true}},
+		{{Field: "target"}, {Field: "weight"}},
+	}
+
+	for _, order := range orders {
+		all, err := store.Query(ctx, fabric.Query{OrderBy: order})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := store.Query(ctx, fabric.Query{OrderBy: order, Offset: 5, Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if fmt.Sprint(all[5:15]) != fmt.Sprint(got) {
+			t.Errorf("order %v: expected %v, got %v", order, all[5:15], got)
+		}
+	}
+}
+
+func BenchmarkInMemoryStore_Query(b *testing.B) {
+	store := &fabric.InMemoryStore{}
+	var triples []fabric.Triple
+	for i := 0; i < 100000; i++ {
+		triples = append(triples, fabric.Triple{
+			Source:    fmt.Sprintf("s%d", i%1000),
+			Predicate: "p",
+			Target:    fmt.Sprintf("t%d", i),
+		})
+	}
+	store.InsertMany(context.Background(), triples)
+
+	q := fabric.Query{Source: fabric.Clause{Type: "eq", Value: "s42"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Query(context.Background(), q)
+	}
+}
+
+func BenchmarkInMemoryStore_QueryLike(b *testing.B) {
+	store := &fabric.InMemoryStore{}
+	var triples []fabric.Triple
+	for i := 0; i < 100000; i++ {
+		triples = append(triples, fabric.Triple{
+			Source:    fmt.Sprintf("s%d", i%1000),
+			Predicate: "p",
+			Target:    fmt.Sprintf("t%d", i),
+		})
+	}
+	store.InsertMany(context.Background(), triples)
+
+	q := fabric.Query{Target: fabric.Clause{Type: "like", Value: "t42*"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Query(context.Background(), q)
+	}
+}
+
+func BenchmarkInMemoryStore_QueryLimit(b *testing.B) {
+	store := &fabric.InMemoryStore{}
+	var triples []fabric.Triple
+	for i := 0; i < 100000; i++ {
+		triples = append(triples, fabric.Triple{
+			Source:    fmt.Sprintf("s%d", i%1000),
+			Predicate: "p",
+			Target:    fmt.Sprintf("t%d", i),
+			Weight:    float64(i % 97),
+		})
+	}
+	store.InsertMany(context.Background(), triples)
+
+	q := fabric.Query{Limit: 10, OrderBy: []fabric.Order{{Field: "weight", Desc: true}}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Query(context.Background(), q)
+	}
+}
+
+func bruteForceMatch(tri fabric.Triple, q fabric.Query) bool {
+	str := func(actual string, cl fabric.Clause) bool {
+		switch cl.Type {
+		case "":
+			return true
+		case "eq":
+			return actual == cl.Value
+		case "like":
+			return len(actual) >= 2 && actual[:2] == cl.Value[:2]
+		}
+		panic("unexpected clause " + cl.Type)
+	}
+
+	if !str(tri.Source, q.Source) || !str(tri.Predicate, q.Predicate) || !str(tri.Target, q.Target) {
+		return false
+	}
+
+	if q.Weight.IsAny() {
+		return true
+	}
+
+	w, _ := strconv.ParseFloat(q.Weight.Value, 64)
+	switch q.Weight.Type {
+	case "eq":
+		return tri.Weight == w
+	case "gt":
+		return tri.Weight > w
+	case "gte":
+		return tri.Weight >= w
+	case "lt":
+		return tri.Weight < w
+	case "lte":
+		return tri.Weight <= w
+	}
+	panic("unexpected clause " + q.Weight.Type)
+}
+
+func sortTriples(triples []fabric.Triple) {
+	sort.Slice(triples, func(i, j int) bool {
+		return triples[i].String() < triples[j].String()
+	})
+}
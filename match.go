@@ -0,0 +1,174 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Pattern represents a single triple pattern. Source, Predicate and Target
+// can either be concrete names or variables of the form '?name'.
+type Pattern struct {
+	Source    string `json:"source"`
+	Predicate string `json:"predicate"`
+	Target    string `json:"target"`
+	Weight    Clause `json:"weight,omitempty"`
+}
+
+func (p Pattern) String() string {
+	return fmt.Sprintf("%s %s %s", p.Source, p.Predicate, p.Target)
+}
+
+// Binding maps variable names (without the '?' prefix) to the values they
+// are bound to.
+type Binding map[string]string
+
+// Match finds all the bindings of the variables in the given patterns such
+// that every pattern matches a triple in the store. If the store implements
+// the Joiner interface, the join is delegated to the store. Otherwise, the
+// patterns are evaluated in order using nested-loop joins over Query.
+func (f *Fabric) Match(ctx context.Context, patterns ...Pattern) ([]Binding, error) {
+	res := []Binding{}
+	err := f.match(ctx, patterns, func(b Binding) (bool, error) {
+		res = append(res, b)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// match invokes fn for every binding that satisfies the patterns. fn should
+// return false to stop the iteration.
+func (f *Fabric) match(ctx context.Context, patterns []Pattern, fn func(Binding) (bool, error)) error {
+	patterns, err := normalizePatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	joiner, ok := f.store.(Joiner)
+	if !ok {
+		return joinPatterns(ctx, f.store, patterns, Binding{}, fn)
+	}
+
+	return joiner.Join(ctx, patterns, fn)
+}
+
+func normalizePatterns(patterns []Pattern) ([]Pattern, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("at least one pattern is required")
+	}
+
+	res := make([]Pattern, len(patterns))
+	for i, pat := range patterns {
+		for _, term := range []string{pat.Source, pat.Predicate, pat.Target} {
+			if strings.TrimSpace(term) == "" || term == "?" {
+				return nil, fmt.Errorf("pattern '%s' has an empty term", pat)
+			}
+		}
+
+		pat.Weight.normalize()
+		res[i] = pat
+	}
+
+	return res, nil
+}
+
+// joinPatterns binds patterns[0] using the given binding and recurses into
+// the rest of the patterns for every triple that matches. fn is invoked for
+// every complete binding and should return false to stop the iteration.
+func joinPatterns(ctx context.Context, store Store, patterns []Pattern, b Binding, fn func(Binding) (bool, error)) error {
+	_, err := joinNext(ctx, store, patterns, b, fn)
+	return err
+}
+
+func joinNext(ctx context.Context, store Store, patterns []Pattern, b Binding, fn func(Binding) (bool, error)) (bool, error) {
+	if len(patterns) == 0 {
+		return fn(b)
+	}
+
+	pat := patterns[0]
+	query := Query{
+		Source:    termClause(pat.Source, b),
+		Predicate: termClause(pat.Predicate, b),
+		Target:    termClause(pat.Target, b),
+		Weight:    pat.Weight,
+	}
+	query.normalize()
+
+	triples, err := store.Query(ctx, query)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tri := range triples {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		next, ok := bindTriple(pat, tri, b)
+		if !ok {
+			continue
+		}
+
+		more, err := joinNext(ctx, store, patterns[1:], next, fn)
+		if err != nil || !more {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// bindTriple extends the binding with the variables of the pattern. Returns
+// false if the triple conflicts with an existing binding (e.g., the same
+// variable used twice in a pattern with different values).
+func bindTriple(pat Pattern, tri Triple, b Binding) (Binding, bool) {
+	next := Binding{}
+	for k, v := range b {
+		next[k] = v
+	}
+
+	terms := [][2]string{
+		{pat.Source, tri.Source},
+		{pat.Predicate, tri.Predicate},
+		{pat.Target, tri.Target},
+	}
+	for _, t := range terms {
+		name, isVar := variableName(t[0])
+		if !isVar {
+			continue
+		}
+
+		if v, bound := next[name]; bound && v != t[1] {
+			return nil, false
+		}
+		next[name] = t[1]
+	}
+
+	return next, true
+}
+
+func termClause(term string, b Binding) Clause {
+	name, isVar := variableName(term)
+	if !isVar {
+		return Clause{Type: "eq", Value: term}
+	}
+
+	if v, bound := b[name]; bound {
+		return Clause{Type: "eq", Value: v}
+	}
+
+	return Clause{}
+}
+
+func variableName(term string) (string, bool) {
+	if strings.HasPrefix(term, "?") {
+		return term[1:], true
+	}
+
+	return "", false
+}
@@ -0,0 +1,128 @@
+package fabric_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_Match(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	for name, newStore := range stores {
+		fab := fabric.New(newStore())
+		for _, tri := range []fabric.Triple{
+			{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1},
+			{Source: "Bob", Predicate: "knows", Target: "Alice", Weight: 0.2},
+			{Source: "John", Predicate: "knows", Target: "Bob"},
+			{Source: "John", Predicate: "works_at", Target: "Acme"},
+			{Source: "Alice", Predicate: "works_at", Target: "Globex"},
+		} {
+			if err := fab.Insert(context.Background(), tri); err != nil {
+				suite.Fatalf("failed to insert: %v", err)
+			}
+		}
+
+		suite.Run(name, func(t *testing.T) {
+			cases := []struct {
+				title     string
+				patterns  []fabric.Pattern
+				expected  []fabric.Binding
+				expectErr bool
+			}{
+				{
+					title:     "NoPatterns",
+					expectErr: true,
+				},
+				{
+					title:     "EmptyTerm",
+					patterns:  []fabric.Pattern{{Source: "Bob", Predicate: "knows"}},
+					expectErr: true,
+				},
+				{
+					title: "FriendsEmployer",
+					patterns: []fabric.Pattern{
+						{Source: "Bob", Predicate: "knows", Target: "?friend"},
+						{Source: "?friend", Predicate: "works_at", Target: "?company"},
+					},
+					expected: []fabric.Binding{
+						{"friend": "John", "company": "Acme"},
+						{"friend": "Alice", "company": "Globex"},
+					},
+				},
+				{
+					title: "WithWeight",
+					patterns: []fabric.Pattern{
+						{Source: "Bob", Predicate: "knows", Target: "?friend", Weight: fabric.Clause{Type: ">", Value: "0.5"}},
+						{Source: "?friend", Predicate: "works_at", Target: "?company"},
+					},
+					expected: []fabric.Binding{
+						{"friend": "John", "company": "Acme"},
+					},
+				},
+				{
+					title: "Mutual",
+					patterns: []fabric.Pattern{
+						{Source: "?a", Predicate: "knows", Target: "?b"},
+						{Source: "?b", Predicate: "knows", Target: "?a"},
+					},
+					expected: []fabric.Binding{
+						{"a": "Bob", "b": "John"},
+						{"a": "John", "b": "Bob"},
+					},
+				},
+				{
+					title: "NoVariables",
+					patterns: []fabric.Pattern{
+						{Source: "Bob", Predicate: "knows", Target: "John"},
+					},
+					expected: []fabric.Binding{{}},
+				},
+			}
+
+			for _, cs := range cases {
+				t.Run(cs.title, func(t *testing.T) {
+					res, err := fab.Match(context.Background(), cs.patterns...)
+					if err != nil {
+						if !cs.expectErr {
+							t.Errorf("unexpected error: %v", err)
+						}
+						return
+					}
+
+					if cs.expectErr {
+						t.Fatalf("expecting error, got nil")
+					}
+
+					sort.Slice(res, func(i, j int) bool {
+						return fmtBinding(res[i]) < fmtBinding(res[j])
+					})
+					if !reflect.DeepEqual(cs.expected, res) {
+						t.Errorf("expected %v, got %v", cs.expected, res)
+					}
+				})
+			}
+		})
+	}
+}
+
+func fmtBinding(b fabric.Binding) string {
+	var keys []string
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	s := ""
+	for _, k := range keys {
+		s += k + "=" + b[k] + " "
+	}
+	return s
+}
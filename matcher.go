@@ -0,0 +1,401 @@
+package fabric
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// matcher is a query compiled for matching triples. Clause values are parsed
+// and patterns are compiled once so that matching a triple does not repeat
+// the work for every triple.
+type matcher struct {
+	source    stringMatcher
+	predicate stringMatcher
+	target    stringMatcher
+	weight    weightMatcher
+	props     map[string]propertyMatcher
+	or        []*matcher
+	not       *matcher
+}
+
+type stringMatcher func(actual string) bool
+
+type weightMatcher func(actual float64) bool
+
+type propertyMatcher func(actual interface{}) bool
+
+// compileQuery compiles the query (including the Or and Not queries) into a
+// matcher. Returns error if any of the clauses cannot be evaluated.
+func compileQuery(query Query) (*matcher, error) {
+	var err error
+	m := &matcher{}
+
+	if m.source, err = compileClause(query.Source); err != nil {
+		return nil, err
+	}
+
+	if m.predicate, err = compileClause(query.Predicate); err != nil {
+		return nil, err
+	}
+
+	if m.target, err = compileTargetClause(query.Target); err != nil {
+		return nil, err
+	}
+
+	if m.weight, err = compileWeightClause(query.Weight); err != nil {
+		return nil, err
+	}
+
+	for key, cl := range query.Properties {
+		pm, err := compilePropertyClause(cl)
+		if err != nil {
+			return nil, err
+		}
+
+		if m.props == nil {
+			m.props = map[string]propertyMatcher{}
+		}
+		m.props[key] = pm
+	}
+
+	for _, q := range query.Or {
+		or, err := compileQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		m.or = append(m.or, or)
+	}
+
+	if query.Not != nil {
+		if m.not, err = compileQuery(*query.Not); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// match returns true if the triple matches the compiled query.
+func (m *matcher) match(tri Triple) bool {
+	if !m.source(tri.Source) || !m.predicate(tri.Predicate) ||
+		!m.target(tri.Target) || !m.weight(tri.Weight) {
+		return false
+	}
+
+	for key, pm := range m.props {
+		val, found := tri.Properties[key]
+		if !found || !pm(val) {
+			return false
+		}
+	}
+
+	if len(m.or) > 0 {
+		matched := false
+		for _, or := range m.or {
+			if or.match(tri) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return m.not == nil || !m.not.match(tri)
+}
+
+func compileClause(clause Clause) (stringMatcher, error) {
+	if clause.IsAny() {
+		return matchAny, nil
+	}
+
+	// clauses may not have been normalized if the store is used directly.
+	clause.normalize()
+	value := clause.Value
+
+	switch clause.Type {
+	case "eq":
+		return func(actual string) bool { return actual == value }, nil
+
+	case "neq":
+		return func(actual string) bool { return actual != value }, nil
+
+	case "in", "not_in":
+		set := map[string]struct{}{}
+		for _, v := range clause.list() {
+			set[v] = struct{}{}
+		}
+
+		in := clause.Type == "in"
+		return func(actual string) bool {
+			_, found := set[actual]
+			return found == in
+		}, nil
+
+	case "prefix":
+		return func(actual string) bool { return strings.HasPrefix(actual, value) }, nil
+
+	case "suffix":
+		return func(actual string) bool { return strings.HasSuffix(actual, value) }, nil
+
+	case "contains":
+		return func(actual string) bool { return strings.Contains(actual, value) }, nil
+
+	case "regex":
+		re, err := compileRegexp(value)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+
+	case "like", "not like":
+		re, err := compileRegexp(likeRegexp(value))
+		if err != nil {
+			return nil, err
+		}
+
+		like := clause.Type == "like"
+		return func(actual string) bool { return re.MatchString(actual) == like }, nil
+	}
+
+	return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedClause, clause.Type)
+}
+
+// compileTargetClause compiles the clause on targets. Range clauses compare
+// the values of literal targets and never match entities.
+func compileTargetClause(clause Clause) (stringMatcher, error) {
+	clause.normalize()
+	if !isNumericClause(clause.Type) {
+		return compileClause(clause)
+	}
+
+	bound, err := parseLiteralBound(clause.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := compareOp(clause.Type)
+	return func(actual string) bool {
+		if !strings.HasPrefix(actual, `"`) {
+			return false
+		}
+
+		obj, err := ParseObject(actual)
+		if err != nil {
+			return false
+		}
+
+		c, ok := bound.compare(obj)
+		return ok && cmp(c)
+	}, nil
+}
+
+// compareOp returns a function reporting whether the result of a comparison
+// satisfies the numeric clause type.
+func compareOp(typ string) func(c int) bool {
+	switch typ {
+	case "gt":
+		return func(c int) bool { return c > 0 }
+	case "gte":
+		return func(c int) bool { return c >= 0 }
+	case "lt":
+		return func(c int) bool { return c < 0 }
+	default:
+		return func(c int) bool { return c <= 0 }
+	}
+}
+
+func compileWeightClause(clause Clause) (weightMatcher, error) {
+	if clause.IsAny() {
+		return func(actual float64) bool { return true }, nil
+	}
+
+	clause.normalize()
+
+	if clause.Type == "in" || clause.Type == "not_in" {
+		set := map[float64]struct{}{}
+		for _, v := range clause.list() {
+			w, err := parseWeight(v)
+			if err != nil {
+				return nil, err
+			}
+			set[w] = struct{}{}
+		}
+
+		in := clause.Type == "in"
+		return func(actual float64) bool {
+			_, found := set[actual]
+			return found == in
+		}, nil
+	}
+
+	w, err := parseWeight(clause.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch clause.Type {
+	case "eq":
+		return func(actual float64) bool { return actual == w }, nil
+
+	case "neq":
+		return func(actual float64) bool { return actual != w }, nil
+
+	case "gte":
+		return func(actual float64) bool { return actual >= w }, nil
+
+	case "lte":
+		return func(actual float64) bool { return actual <= w }, nil
+
+	case "gt":
+		return func(actual float64) bool { return actual > w }, nil
+
+	case "lt":
+		return func(actual float64) bool { return actual < w }, nil
+	}
+
+	return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedClause, clause.Type)
+}
+
+// compilePropertyClause compiles the clause on a property value. Numeric
+// comparisons match only numbers while the other clauses match the string
+// form of the value.
+func compilePropertyClause(clause Clause) (propertyMatcher, error) {
+	clause.normalize()
+
+	if isNumericClause(clause.Type) {
+		wm, err := compileWeightClause(clause)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(actual interface{}) bool {
+			f, isNum := actual.(float64)
+			return isNum && wm(f)
+		}, nil
+	}
+
+	sm, err := compileClause(clause)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(actual interface{}) bool {
+		return sm(propertyText(actual))
+	}, nil
+}
+
+func matchAny(actual string) bool {
+	return true
+}
+
+func parseWeight(s string) (float64, error) {
+	w, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: weight must be a number, got '%s'", ErrInvalidClause, s)
+	}
+	return w, nil
+}
+
+// MatchRegexp reports whether the value contains a match of the regular
+// expression pattern (RE2 syntax, as used by the regex clause type). It can
+// be registered as the REGEXP function of sqlite to support regex clauses in
+// SQLStore. Compiled patterns are cached.
+func MatchRegexp(pattern, value string) (bool, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(value), nil
+}
+
+// likeRegexp converts the like pattern into an equivalent regular expression.
+// Like patterns match the whole value ignoring case, with '*' matching any
+// sequence of characters and all the other characters matching themselves.
+func likeRegexp(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return "(?is)^" + strings.Join(parts, ".*") + "$"
+}
+
+// regexpCacheSize is the number of compiled regular expressions retained
+// across queries.
+const regexpCacheSize = 256
+
+var regexps = newRegexpCache(regexpCacheSize)
+
+// compileRegexp compiles the expression using the shared cache.
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, found := regexps.get(expr); found {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClause, err)
+	}
+
+	regexps.put(expr, re)
+	return re, nil
+}
+
+// regexpCache is a bounded LRU cache of compiled regular expressions.
+type regexpCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type regexpEntry struct {
+	expr string
+	re   *regexp.Regexp
+}
+
+func newRegexpCache(size int) *regexpCache {
+	return &regexpCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (rc *regexpCache) get(expr string) (*regexp.Regexp, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	el, found := rc.entries[expr]
+	if !found {
+		return nil, false
+	}
+
+	rc.order.MoveToFront(el)
+	return el.Value.(*regexpEntry).re, true
+}
+
+func (rc *regexpCache) put(expr string, re *regexp.Regexp) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if el, found := rc.entries[expr]; found {
+		rc.order.MoveToFront(el)
+		return
+	}
+
+	rc.entries[expr] = rc.order.PushFront(&regexpEntry{expr: expr, re: re})
+	for rc.order.Len() > rc.size {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*regexpEntry).expr)
+	}
+}
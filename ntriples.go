@@ -0,0 +1,76 @@
+package fabric
+
+import (
+	"bufio"
+	"io"
+)
+
+// NTriplesReader reads triples from an N-Triples document. See RDFOptions
+// for the mapping of RDF terms onto triples.
+type NTriplesReader struct {
+	parser *rdfParser
+}
+
+// NewNTriplesReader returns a reader for the N-Triples document in r.
+func NewNTriplesReader(r io.Reader, opts RDFOptions) *NTriplesReader {
+	return &NTriplesReader{parser: newRDFParser(r, opts, false)}
+}
+
+// Read returns the next triple from the document. Returns io.EOF when there
+// are no more triples. Triples that cannot be converted (e.g., literals of
+// unsupported datatypes) are reported using a LineError and reading can be
+// continued.
+func (nr *NTriplesReader) Read() (Triple, error) {
+	return nr.parser.read()
+}
+
+// NTriplesWriter writes triples as N-Triples statements. Writes are buffered
+// and Flush must be called after the last triple.
+type NTriplesWriter struct {
+	w    *bufio.Writer
+	opts RDFOptions
+}
+
+// NewNTriplesWriter returns a writer writing N-Triples to w.
+func NewNTriplesWriter(w io.Writer, opts RDFOptions) *NTriplesWriter {
+	return &NTriplesWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+// Write writes the triple as an N-Triples statement.
+func (nw *NTriplesWriter) Write(tri Triple) error {
+	subject, object, err := nw.opts.terms(tri)
+	if err != nil {
+		return err
+	}
+
+	predicate := rdfTerm{kind: termIRI, value: nw.opts.iri(tri.Predicate)}
+
+	_, err = nw.w.WriteString(ntTerm(subject) + " " + ntTerm(predicate) + " " + ntTerm(object) + " .\n")
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (nw *NTriplesWriter) Flush() error {
+	return nw.w.Flush()
+}
+
+// ntTerm returns the N-Triples form of the term.
+func ntTerm(term rdfTerm) string {
+	switch term.kind {
+	case termBlank:
+		return "_:" + term.value
+
+	case termIRI:
+		return "<" + term.value + ">"
+	}
+
+	s := quoteLiteral(term.value)
+	if term.lang != "" {
+		return s + "@" + term.lang
+	}
+
+	if term.datatype != "" {
+		return s + "^^<" + term.datatype + ">"
+	}
+	return s
+}
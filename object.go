@@ -0,0 +1,311 @@
+package fabric
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Datatype is the type of a literal object.
+type Datatype string
+
+// Datatypes supported for literal objects.
+const (
+	String Datatype = "string"
+	Int    Datatype = "int"
+	Float  Datatype = "float"
+	Bool   Datatype = "bool"
+	Date   Datatype = "date"
+)
+
+// dateLayout is the lexical form of date literals.
+const dateLayout = "2006-01-02"
+
+// Object is the typed form of the target of a triple. A target is either an
+// entity (i.e., a node of the graph) or a literal value with a datatype and,
+// for strings, an optional language tag. Literals are stored in the target
+// of a triple in the encoded form returned by String (e.g., '"42"^^int' or
+// '"hello"@en') which keeps "Bob age 42" distinct from an entity named "42".
+type Object struct {
+	// Value is the name of the entity or the lexical form of the literal.
+	Value string `json:"value"`
+
+	// Literal is true if the object is a literal value.
+	Literal bool `json:"literal,omitempty"`
+
+	// Datatype of the literal. Empty is the same as String.
+	Datatype Datatype `json:"datatype,omitempty"`
+
+	// Lang is the language tag of a string literal.
+	Lang string `json:"lang,omitempty"`
+}
+
+// Entity returns an object referring to the named entity.
+func Entity(name string) Object {
+	return Object{Value: name}
+}
+
+// Literal returns a literal object with the lexical value and datatype.
+func Literal(value string, datatype Datatype) Object {
+	return Object{Value: value, Literal: true, Datatype: datatype}
+}
+
+// LangLiteral returns a string literal with the language tag.
+func LangLiteral(value, lang string) Object {
+	return Object{Value: value, Literal: true, Datatype: String, Lang: lang}
+}
+
+// ParseObject parses the target of a triple. Targets that are not encoded
+// literals (i.e., not starting with '"') are entities. Literal values are
+// validated against their datatype and converted to the canonical form (e.g.,
+// '"042"^^int' becomes '"42"^^int').
+func ParseObject(target string) (Object, error) {
+	if !strings.HasPrefix(target, `"`) {
+		return Entity(target), nil
+	}
+
+	value, rest, err := unquoteLiteral(target)
+	if err != nil {
+		return Object{}, err
+	}
+
+	obj := Object{Value: value, Literal: true, Datatype: String}
+	switch {
+	case rest == "":
+
+	case strings.HasPrefix(rest, "@"):
+		obj.Lang = strings.ToLower(rest[1:])
+		if obj.Lang == "" {
+			return Object{}, fmt.Errorf("empty language tag in literal %s", target)
+		}
+
+	case strings.HasPrefix(rest, "^^"):
+		obj.Datatype = Datatype(rest[2:])
+
+	default:
+		return Object{}, fmt.Errorf("unexpected '%s' after literal value", rest)
+	}
+
+	return obj.canonical()
+}
+
+// Native returns the value of the object as a Go value: string for entities
+// and string literals, int64, float64, bool or time.Time for the other
+// datatypes.
+func (o Object) Native() (interface{}, error) {
+	if !o.Literal {
+		return o.Value, nil
+	}
+
+	switch o.datatype() {
+	case String:
+		return o.Value, nil
+
+	case Int:
+		return strconv.ParseInt(o.Value, 10, 64)
+
+	case Float:
+		return strconv.ParseFloat(o.Value, 64)
+
+	case Bool:
+		return strconv.ParseBool(o.Value)
+
+	case Date:
+		return time.Parse(dateLayout, o.Value)
+	}
+
+	return nil, fmt.Errorf("unknown datatype '%s'", o.Datatype)
+}
+
+// String returns the encoded form of the object for use as the target of a
+// triple.
+func (o Object) String() string {
+	if !o.Literal {
+		return o.Value
+	}
+
+	s := quoteLiteral(o.Value)
+	if o.Lang != "" {
+		return s + "@" + o.Lang
+	}
+
+	if dt := o.datatype(); dt != String {
+		return s + "^^" + string(dt)
+	}
+	return s
+}
+
+// Object returns the typed form of the target of the triple.
+func (tri Triple) Object() (Object, error) {
+	return ParseObject(tri.Target)
+}
+
+func (o Object) datatype() Datatype {
+	if o.Datatype == "" {
+		return String
+	}
+	return o.Datatype
+}
+
+// canonical validates the literal value and converts it into the canonical
+// lexical form of its datatype.
+func (o Object) canonical() (Object, error) {
+	if !o.Literal {
+		return o, nil
+	}
+
+	o.Datatype = o.datatype()
+	if o.Lang != "" && o.Datatype != String {
+		return Object{}, fmt.Errorf("language tag is not allowed on %s literals", o.Datatype)
+	} else if o.Lang != "" && !langTag.MatchString(o.Lang) {
+		return Object{}, fmt.Errorf("invalid language tag '%s'", o.Lang)
+	}
+
+	v, err := o.Native()
+	if err != nil {
+		return Object{}, fmt.Errorf("invalid %s literal '%s'", o.Datatype, o.Value)
+	}
+
+	switch val := v.(type) {
+	case int64:
+		o.Value = strconv.FormatInt(val, 10)
+
+	case float64:
+		o.Value = strconv.FormatFloat(val, 'f', -1, 64)
+
+	case bool:
+		o.Value = strconv.FormatBool(val)
+
+	case time.Time:
+		o.Value = val.Format(dateLayout)
+	}
+
+	return o, nil
+}
+
+// langTag matches the BCP47 language tags (e.g., 'en', 'en-us').
+var langTag = regexp.MustCompile(`^[A-Za-z]+(-[A-Za-z0-9]+)*$`)
+
+var literalEscapes = map[byte]byte{'\\': '\\', '"': '"', 'n': '\n', 'r': '\r', 't': '\t'}
+
+func quoteLiteral(value string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\', '"':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// unquoteLiteral reads the quoted value at the beginning of s and returns the
+// unescaped value along with the remainder of s.
+func unquoteLiteral(s string) (string, string, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return sb.String(), s[i+1:], nil
+
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated literal %s", s)
+			}
+
+			esc, found := literalEscapes[s[i+1]]
+			if !found {
+				return "", "", fmt.Errorf("invalid escape '\\%c' in literal %s", s[i+1], s)
+			}
+			sb.WriteByte(esc)
+			i++
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", "", fmt.Errorf("unterminated literal %s", s)
+}
+
+// literalBound is the value of a range clause on literal targets. Numbers
+// are compared with int and float literals and dates with date literals.
+type literalBound struct {
+	date bool
+	num  float64
+	lex  string
+}
+
+// parseLiteralBound parses the value of a range clause on targets which can
+// be a number, a date (YYYY-MM-DD) or an encoded int, float or date literal.
+func parseLiteralBound(value string) (literalBound, error) {
+	if strings.HasPrefix(value, `"`) {
+		obj, err := ParseObject(value)
+		if err != nil {
+			return literalBound{}, fmt.Errorf("%w: %v", ErrInvalidClause, err)
+		}
+
+		switch obj.Datatype {
+		case Int, Float, Date:
+			value = obj.Value
+
+		default:
+			return literalBound{}, fmt.Errorf("%w: range clauses are not supported on %s literals", ErrInvalidClause, obj.Datatype)
+		}
+	}
+
+	if num, err := strconv.ParseFloat(value, 64); err == nil {
+		return literalBound{num: num}, nil
+	}
+
+	if d, err := time.Parse(dateLayout, value); err == nil {
+		return literalBound{date: true, lex: d.Format(dateLayout)}, nil
+	}
+
+	return literalBound{}, fmt.Errorf("%w: target range must be a number or a date, got '%s'", ErrInvalidClause, value)
+}
+
+// compare compares the literal with the bound. Returns false if the literal
+// is not comparable with the bound.
+func (b literalBound) compare(obj Object) (int, bool) {
+	if !obj.Literal {
+		return 0, false
+	}
+
+	if b.date {
+		if obj.Datatype != Date {
+			return 0, false
+		}
+		return strings.Compare(obj.Value, b.lex), true
+	}
+
+	if obj.Datatype != Int && obj.Datatype != Float {
+		return 0, false
+	}
+
+	num, err := strconv.ParseFloat(obj.Value, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	switch {
+	case num < b.num:
+		return -1, true
+	case num > b.num:
+		return 1, true
+	}
+	return 0, true
+}
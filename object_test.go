@@ -0,0 +1,163 @@
+package fabric_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spy16/fabric"
+)
+
+func TestParseObject(suite *testing.T) {
+	suite.Parallel()
+
+	cases := []struct {
+		title     string
+		target    string
+		expected  fabric.Object
+		encoded   string
+		native    interface{}
+		expectErr bool
+	}{
+		{
+			title:    "Entity",
+			target:   "Bob",
+			expected: fabric.Entity("Bob"),
+			encoded:  "Bob",
+			native:   "Bob",
+		},
+		{
+			title:    "PlainString",
+			target:   `"hello world"`,
+			expected: fabric.Literal("hello world", fabric.String),
+			encoded:  `"hello world"`,
+			native:   "hello world",
+		},
+		{
+			title:    "ExplicitString",
+			target:   `"hello"^^string`,
+			expected: fabric.Literal("hello", fabric.String),
+			encoded:  `"hello"`,
+			native:   "hello",
+		},
+		{
+			title:    "LangString",
+			target:   `"bonjour"@FR`,
+			expected: fabric.LangLiteral("bonjour", "fr"),
+			encoded:  `"bonjour"@fr`,
+			native:   "bonjour",
+		},
+		{
+			title:    "Escapes",
+			target:   `"say \"hi\"\n\\"`,
+			expected: fabric.Literal("say \"hi\"\n\\", fabric.String),
+			encoded:  `"say \"hi\"\n\\"`,
+			native:   "say \"hi\"\n\\",
+		},
+		{
+			title:    "Int",
+			target:   `"042"^^int`,
+			expected: fabric.Literal("42", fabric.Int),
+			encoded:  `"42"^^int`,
+			native:   int64(42),
+		},
+		{
+			title:    "Float",
+			target:   `"1.50"^^float`,
+			expected: fabric.Literal("1.5", fabric.Float),
+			encoded:  `"1.5"^^float`,
+			native:   1.5,
+		},
+		{
+			title:    "Bool",
+			target:   `"TRUE"^^bool`,
+			expected: fabric.Literal("true", fabric.Bool),
+			encoded:  `"true"^^bool`,
+			native:   true,
+		},
+		{
+			title:    "Date",
+			target:   `"2020-01-02"^^date`,
+			expected: fabric.Literal("2020-01-02", fabric.Date),
+			encoded:  `"2020-01-02"^^date`,
+			native:   time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			title:     "InvalidInt",
+			target:    `"4.2"^^int`,
+			expectErr: true,
+		},
+		{
+			title:     "InvalidDate",
+			target:    `"2020-13-01"^^date`,
+			expectErr: true,
+		},
+		{
+			title:     "UnknownDatatype",
+			target:    `"x"^^uuid`,
+			expectErr: true,
+		},
+		{
+			title:     "LangOnNonString",
+			target:    `"1"^^int@en`,
+			expectErr: true,
+		},
+		{
+			title:     "InvalidLangTag",
+			target:    `"x"@a"^^int`,
+			expectErr: true,
+		},
+		{
+			title:     "LangTagWithUnderscore",
+			target:    `"x"@en_us`,
+			expectErr: true,
+		},
+		{
+			title:     "Unterminated",
+			target:    `"hello`,
+			expectErr: true,
+		},
+		{
+			title:     "InvalidEscape",
+			target:    `"\x"`,
+			expectErr: true,
+		},
+		{
+			title:     "TrailingCharacters",
+			target:    `"x"y`,
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			obj, err := fabric.ParseObject(cs.target)
+			if err != nil {
+				if !cs.expectErr {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if cs.expectErr {
+				t.Fatalf("expecting error, got %v", obj)
+			}
+
+			if !reflect.DeepEqual(cs.expected, obj) {
+				t.Errorf("expected %#v, got %#v", cs.expected, obj)
+			}
+
+			if obj.String() != cs.encoded {
+				t.Errorf("expected encoded form '%s', got '%s'", cs.encoded, obj.String())
+			}
+
+			native, err := obj.Native()
+			if err != nil {
+				t.Fatalf("unexpected error from Native: %v", err)
+			}
+			if !reflect.DeepEqual(cs.native, native) {
+				t.Errorf("expected native value %#v, got %#v", cs.native, native)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package fabric
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Page represents a single page of query results.
+type Page struct {
+	Triples []Triple `json:"triples"`
+
+	// Next is the cursor to be set on the query to fetch the next page. It
+	// is empty if there are no more results.
+	Next string `json:"next,omitempty"`
+}
+
+// QueryPage finds the triples matching the query one page at a time. The
+// page size is set by the Limit of the query and the returned Page contains
+// the cursor to be set on the query to fetch the next page. If the query has
+// no limit, all the remaining triples are returned as a single page.
+func (f *Fabric) QueryPage(ctx context.Context, query Query) (Page, error) {
+	query.normalize()
+
+	keys, err := query.sortKeys()
+	if err != nil {
+		return Page{}, err
+	}
+
+	if query.Limit <= 0 {
+		triples, err := f.store.Query(ctx, query)
+		return Page{Triples: triples}, err
+	}
+
+	// fetch one extra triple to find out if there is a next page.
+	limit := query.Limit
+	query.Limit++
+	triples, err := f.store.Query(ctx, query)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{Triples: triples}
+	if len(triples) > limit {
+		page.Triples = triples[:limit]
+		page.Next, err = encodeCursor(page.Triples[limit-1], keys)
+		if err != nil {
+			return Page{}, err
+		}
+	}
+	return page, nil
+}
+
+// cursor is the decoded form of Query.Cursor. It holds the sort key values
+// of the last triple in the previous page along with the order in which the
+// page was fetched.
+type cursor struct {
+	Order     string  `json:"o"`
+	Source    string  `json:"s"`
+	Predicate string  `json:"p"`
+	Target    string  `json:"t"`
+	Weight    float64 `json:"w"`
+}
+
+func encodeCursor(last Triple, keys []Order) (string, error) {
+	data, err := json.Marshal(cursor{
+		Order:     orderString(keys),
+		Source:    last.Source,
+		Predicate: last.Predicate,
+		Target:    last.Target,
+		Weight:    last.Weight,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// after decodes the cursor of the query and returns the triple after which
+// the results should start. Returns nil if the query has no cursor.
+func (q Query) after(keys []Order) (*Triple, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	if cur.Order != orderString(keys) {
+		return nil, fmt.Errorf("%w: cursor does not match the query order", ErrInvalidQuery)
+	}
+
+	return &Triple{
+		Source:    cur.Source,
+		Predicate: cur.Predicate,
+		Target:    cur.Target,
+		Weight:    cur.Weight,
+	}, nil
+}
+
+// skip returns the number of triples to skip as per the offset. The offset
+// applies only to the first page.
+func (q Query) skip() int {
+	if q.Cursor != "" {
+		return 0
+	}
+	return q.Offset
+}
+
+// paginate applies the cursor, offset and limit of the query to triples
+// sorted using the given keys.
+func paginate(triples []Triple, query Query, keys []Order) ([]Triple, error) {
+	last, err := query.after(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if last != nil {
+		start := sort.Search(len(triples), func(i int) bool {
+			return compareTriples(triples[i], *last, keys) > 0
+		})
+		triples = triples[start:]
+	}
+
+	if offset := query.skip(); offset > 0 {
+		if offset >= len(triples) {
+			return []Triple{}, nil
+		}
+		triples = triples[offset:]
+	}
+
+	if query.Limit > 0 && len(triples) > query.Limit {
+		triples = triples[:query.Limit]
+	}
+
+	return triples, nil
+}
+
+func orderString(keys []Order) string {
+	terms := make([]string, len(keys))
+	for i, key := range keys {
+		terms[i] = key.String()
+	}
+	return strings.Join(terms, ",")
+}
@@ -0,0 +1,125 @@
+package fabric_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_QueryPage(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	var triples []fabric.Triple
+	for i := 0; i < 11; i++ {
+		triples = append(triples, fabric.Triple{
+			Source:    fmt.Sprintf("s%d", i%3),
+			Predicate: "p",
+			Target:    fmt.Sprintf("t%d", i),
+			Weight:    float64(i % 4),
+		})
+	}
+
+	orders := [][]fabric.Order{
+		nil,
+		{{Field: "weight", Desc: true}},
+		{{Field: "target", Desc: true}, {Field: "source"}},
+	}
+
+	for name, newStore := range stores {
+		ctx := context.Background()
+		fab := fabric.New(newStore())
+		if _, err := fab.InsertMany(ctx, triples); err != nil {
+			suite.Fatalf("failed to insert: %v", err)
+		}
+
+		suite.Run(name, func(t *testing.T) {
+			for _, order := range orders {
+				t.Run(fmt.Sprintf("%v", order), func(t *testing.T) {
+					expected, err := fab.Query(ctx, fabric.Query{OrderBy: order})
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					var got []fabric.Triple
+					q := fabric.Query{OrderBy: order, Limit: 3}
+					for pages := 0; ; pages++ {
+						if pages > len(triples) {
+							t.Fatalf("pagination did not terminate")
+						}
+
+						page, err := fab.QueryPage(ctx, q)
+						if err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+						got = append(got, page.Triples...)
+
+						if page.Next == "" {
+							break
+						}
+						q.Cursor = page.Next
+					}
+
+					if !reflect.DeepEqual(expected, got) {
+						t.Errorf("expected %v, got %v", expected, got)
+					}
+				})
+			}
+
+			t.Run("Offset", func(t *testing.T) {
+				got, err := fab.Query(ctx, fabric.Query{Offset: 9, Limit: 5})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				all, _ := fab.Query(ctx, fabric.Query{})
+				if !reflect.DeepEqual(all[9:], got) {
+					t.Errorf("expected %v, got %v", all[9:], got)
+				}
+			})
+
+			t.Run("InvalidCursor", func(t *testing.T) {
+				_, err := fab.QueryPage(ctx, fabric.Query{Cursor: "not-a-cursor", Limit: 2})
+				if !errors.Is(err, fabric.ErrInvalidQuery) {
+					t.Errorf("expecting ErrInvalidQuery, got %v", err)
+				}
+			})
+
+			t.Run("CursorOrderMismatch", func(t *testing.T) {
+				page, err := fab.QueryPage(ctx, fabric.Query{Limit: 2})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				_, err = fab.QueryPage(ctx, fabric.Query{
+					Cursor:  page.Next,
+					OrderBy: []fabric.Order{{Field: "weight"}},
+					Limit:   2,
+				})
+				if !errors.Is(err, fabric.ErrInvalidQuery) {
+					t.Errorf("expecting ErrInvalidQuery, got %v", err)
+				}
+			})
+		})
+	}
+
+	suite.Run("UnencodableCursor", func(t *testing.T) {
+		fab := fabric.New(&fabric.InMemoryStore{})
+		insert(t, fab,
+			fabric.Triple{Source: "a", Predicate: "p", Target: "b", Weight: math.Inf(1)},
+			fabric.Triple{Source: "a", Predicate: "p", Target: "c"},
+		)
+
+		if _, err := fab.QueryPage(context.Background(), fabric.Query{Limit: 1}); err == nil {
+			t.Errorf("expecting error, got nil")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package fabric
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoPath is returned when there is no path between two nodes. It is a
+// not-found error, i.e., errors.Is(ErrNoPath, ErrNotFound) is true.
+var ErrNoPath error = notFoundError("no path found")
+
+// PathOptions can be used to control the path search done by ShortestPath.
+type PathOptions struct {
+	// Predicates restricts the edges that can be part of the path. If empty,
+	// edges with any predicate can be used.
+	Predicates []string `json:"predicates,omitempty"`
+
+	// MaxDepth is the maximum number of edges in the path. Zero means there
+	// is no limit.
+	MaxDepth int `json:"max_depth,omitempty"`
+
+	// Undirected allows edges to be traversed from target to source as well.
+	Undirected bool `json:"undirected,omitempty"`
+
+	// Weighted uses Dijkstra's algorithm to find the path with the lowest
+	// total weight instead of the path with the fewest edges.
+	Weighted bool `json:"weighted,omitempty"`
+}
+
+// ShortestPath finds the shortest path from one node to another and returns
+// the triples making up the path in order. If the nodes are not connected,
+// ErrNoPath is returned. Weighted search requires all the weights on the
+// traversed edges to be non-negative.
+func (f *Fabric) ShortestPath(ctx context.Context, from, to string, opts PathOptions) ([]Triple, error) {
+	if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+		return nil, errors.New("both from and to nodes must be specified")
+	}
+
+	if opts.MaxDepth < 0 {
+		return nil, errors.New("max depth must not be negative")
+	}
+
+	if from == to {
+		return []Triple{}, nil
+	}
+
+	direction := Outgoing
+	if opts.Undirected {
+		direction = Both
+	}
+
+	pf := &pathFinder{
+		opts: opts,
+		lister: edgeLister{
+			store:      f.store,
+			direction:  direction,
+			predicates: opts.Predicates,
+		},
+	}
+	return pf.find(ctx, from, to)
+}
+
+type pathFinder struct {
+	opts   PathOptions
+	lister edgeLister
+}
+
+// find runs a best-first search from 'from'. With unweighted search every
+// edge costs 1 and the search degenerates to a breadth-first search.
+func (pf *pathFinder) find(ctx context.Context, from, to string) ([]Triple, error) {
+	start := pathState{node: from}
+	prev := map[pathState]pathStep{}
+	cost := map[pathState]float64{start: 0}
+
+	pq := &pathQueue{{state: start}}
+	for pq.Len() > 0 {
+		cur := heap.Pop(pq).(pathItem)
+		if cur.cost > cost[cur.state] {
+			continue // stale entry
+		}
+
+		if cur.state.node == to {
+			return pf.unwind(prev, cur.state), nil
+		}
+
+		if pf.opts.MaxDepth > 0 && cur.state.depth >= pf.opts.MaxDepth {
+			continue
+		}
+
+		edges, err := pf.lister.edges(ctx, cur.state.node)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range edges {
+			edgeCost := 1.0
+			if pf.opts.Weighted {
+				if e.tri.Weight < 0 {
+					return nil, fmt.Errorf("negative weight on edge '%s'", e.tri)
+				}
+				edgeCost = e.tri.Weight
+			}
+
+			next := pathState{node: e.next}
+			if pf.opts.MaxDepth > 0 {
+				// with a depth limit, the same node reached with fewer hops
+				// may still lead to a path where a cheaper one cannot.
+				next.depth = cur.state.depth + 1
+			}
+
+			c := cur.cost + edgeCost
+			if old, seen := cost[next]; seen && old <= c {
+				continue
+			}
+
+			cost[next] = c
+			prev[next] = pathStep{from: cur.state, tri: e.tri}
+			heap.Push(pq, pathItem{state: next, cost: c})
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+func (pf *pathFinder) unwind(prev map[pathState]pathStep, end pathState) []Triple {
+	var path []Triple
+	for cur := end; ; {
+		step, found := prev[cur]
+		if !found {
+			break
+		}
+		path = append(path, step.tri)
+		cur = step.from
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type pathState struct {
+	node  string
+	depth int
+}
+
+type pathStep struct {
+	from pathState
+	tri  Triple
+}
+
+type pathItem struct {
+	state pathState
+	cost  float64
+}
+
+// pathQueue is a min-heap of path items ordered by cost.
+type pathQueue []pathItem
+
+func (pq pathQueue) Len() int            { return len(pq) }
+func (pq pathQueue) Less(i, j int) bool  { return pq[i].cost < pq[j].cost }
+func (pq pathQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *pathQueue) Push(x interface{}) { *pq = append(*pq, x.(pathItem)) }
+
+func (pq *pathQueue) Pop() interface{} {
+	old := *pq
+	item := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,106 @@
+package fabric_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_ShortestPath(suite *testing.T) {
+	suite.Parallel()
+
+	fab := fabric.New(&fabric.InMemoryStore{})
+	for _, tri := range []fabric.Triple{
+		{Source: "a", Predicate: "road", Target: "b", Weight: 5},
+		{Source: "b", Predicate: "road", Target: "d", Weight: 5},
+		{Source: "a", Predicate: "road", Target: "c", Weight: 1},
+		{Source: "c", Predicate: "rail", Target: "e", Weight: 1},
+		{Source: "e", Predicate: "road", Target: "d", Weight: 1},
+		{Source: "f", Predicate: "road", Target: "a", Weight: 1},
+	} {
+		if err := fab.Insert(context.Background(), tri); err != nil {
+			suite.Fatalf("failed to insert: %v", err)
+		}
+	}
+
+	edge := func(src, pred, dst string, w float64) fabric.Triple {
+		return fabric.Triple{Source: src, Predicate: pred, Target: dst, Weight: w}
+	}
+
+	cases := []struct {
+		title     string
+		from, to  string
+		opts      fabric.PathOptions
+		expected  []fabric.Triple
+		expectErr error
+	}{
+		{
+			title:    "SameNode",
+			from:     "a",
+			to:       "a",
+			expected: []fabric.Triple{},
+		},
+		{
+			title:    "FewestHops",
+			from:     "a",
+			to:       "d",
+			expected: []fabric.Triple{edge("a", "road", "b", 5), edge("b", "road", "d", 5)},
+		},
+		{
+			title:    "Weighted",
+			from:     "a",
+			to:       "d",
+			opts:     fabric.PathOptions{Weighted: true},
+			expected: []fabric.Triple{edge("a", "road", "c", 1), edge("c", "rail", "e", 1), edge("e", "road", "d", 1)},
+		},
+		{
+			title:    "WeightedWithMaxDepth",
+			from:     "a",
+			to:       "d",
+			opts:     fabric.PathOptions{Weighted: true, MaxDepth: 2},
+			expected: []fabric.Triple{edge("a", "road", "b", 5), edge("b", "road", "d", 5)},
+		},
+		{
+			title:     "MaxDepthTooSmall",
+			from:      "a",
+			to:        "d",
+			opts:      fabric.PathOptions{MaxDepth: 1},
+			expectErr: fabric.ErrNoPath,
+		},
+		{
+			title:    "PredicateFilter",
+			from:     "a",
+			to:       "d",
+			opts:     fabric.PathOptions{Weighted: true, Predicates: []string{"road"}},
+			expected: []fabric.Triple{edge("a", "road", "b", 5), edge("b", "road", "d", 5)},
+		},
+		{
+			title:     "Directed",
+			from:      "d",
+			to:        "f",
+			expectErr: fabric.ErrNoPath,
+		},
+		{
+			title:    "Undirected",
+			from:     "c",
+			to:       "f",
+			opts:     fabric.PathOptions{Undirected: true},
+			expected: []fabric.Triple{edge("a", "road", "c", 1), edge("f", "road", "a", 1)},
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			path, err := fab.ShortestPath(context.Background(), cs.from, cs.to, cs.opts)
+			if err != cs.expectErr {
+				t.Fatalf("expected error '%v', got '%v'", cs.expectErr, err)
+			}
+
+			if !reflect.DeepEqual(cs.expected, path) {
+				t.Errorf("expected %v, got %v", cs.expected, path)
+			}
+		})
+	}
+}
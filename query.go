@@ -1,7 +1,10 @@
 package fabric
 
 import (
+	"container/heap"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // Query represents a query to identify one or more triples.
@@ -11,12 +14,43 @@ type Query struct {
 	Target    Clause `json:"target,omitempty"`
 	Weight    Clause `json:"weight,omitempty"`
 	Limit     int    `json:"limit,omitempty"`
+
+	// Properties maps property names to clauses on their values. Triples
+	// without the property do not match. The gt, gte, lt and lte clauses
+	// match numeric values while all the other clauses match the string form
+	// of the value (e.g., 'true', '2.5').
+	Properties map[string]Clause `json:"properties,omitempty"`
+
+	// Offset skips the given number of triples. It is ignored if Cursor is
+	// set since the cursor returned for a page already accounts for it.
+	Offset int `json:"offset,omitempty"`
+
+	// Cursor is an opaque value returned by Fabric.QueryPage. If set, only
+	// the triples after the last triple of the previous page are returned.
+	// The query must use the same order as the query that produced it.
+	Cursor string `json:"cursor,omitempty"`
+
+	// Or matches triples matching any one of the queries in addition to the
+	// clauses above. Limit, Offset, Cursor and OrderBy of these queries are
+	// ignored.
+	Or []Query `json:"or,omitempty"`
+
+	// Not excludes the triples matching the query. Limit, Offset, Cursor and
+	// OrderBy of the query are ignored.
+	Not *Query `json:"not,omitempty"`
+
+	// OrderBy specifies the sort keys for the result. Results are always
+	// ordered by source, predicate and target after the given keys so that
+	// the order (and the triples selected by Limit) is deterministic.
+	OrderBy []Order `json:"order_by,omitempty"`
 }
 
-// IsAny returns true if all clauses are any clauses.
+// IsAny returns true if all clauses are any clauses and there are no
+// property clauses, Or or Not queries.
 func (q Query) IsAny() bool {
 	return (q.Source.IsAny() && q.Predicate.IsAny() &&
-		q.Target.IsAny() && q.Weight.IsAny())
+		q.Target.IsAny() && q.Weight.IsAny() && len(q.Properties) == 0 &&
+		len(q.Or) == 0 && q.Not == nil)
 }
 
 // Map returns a map version of the query with all the any clauses removed.
@@ -46,6 +80,222 @@ func (q *Query) normalize() {
 	q.Target.normalize()
 	q.Predicate.normalize()
 	q.Weight.normalize()
+
+	// Properties, Or, Not and OrderBy are copied to avoid modifying the
+	// caller's queries.
+	if q.Properties != nil {
+		props := make(map[string]Clause, len(q.Properties))
+		for key, cl := range q.Properties {
+			cl.normalize()
+			props[key] = cl
+		}
+		q.Properties = props
+	}
+
+	q.Or = append([]Query(nil), q.Or...)
+	for i := range q.Or {
+		q.Or[i].normalize()
+	}
+
+	if q.Not != nil {
+		not := *q.Not
+		not.normalize()
+		q.Not = &not
+	}
+
+	q.OrderBy = append([]Order(nil), q.OrderBy...)
+	for i := range q.OrderBy {
+		q.OrderBy[i].Field = strings.ToLower(strings.TrimSpace(q.OrderBy[i].Field))
+	}
+}
+
+// unpaged returns the query without Limit, Offset and Cursor. Counts and
+// changes made using a query apply to all the matching triples.
+func (q Query) unpaged() Query {
+	q.Limit, q.Offset, q.Cursor = 0, 0, ""
+	return q
+}
+
+// sortKeys returns the validated sort keys of the query followed by the
+// source, predicate and target keys that are not already present.
+func (q Query) sortKeys() ([]Order, error) {
+	seen := map[string]bool{}
+	var keys []Order
+	for _, o := range q.OrderBy {
+		if !isOrderField(o.Field) {
+			return nil, fmt.Errorf("%w: cannot order by '%s'", ErrInvalidQuery, o.Field)
+		}
+
+		if !seen[o.Field] {
+			seen[o.Field] = true
+			keys = append(keys, o)
+		}
+	}
+
+	for _, f := range []string{"source", "predicate", "target"} {
+		if !seen[f] {
+			keys = append(keys, Order{Field: f})
+		}
+	}
+
+	return keys, nil
+}
+
+// Order represents a sort key of a query.
+type Order struct {
+	// Field is one of source, predicate, target or weight.
+	Field string `json:"field"`
+
+	// Desc sorts in descending order if true.
+	Desc bool `json:"desc,omitempty"`
+}
+
+// ParseOrder parses a comma separated list of sort keys of the form
+// 'field [asc|desc]' (e.g., 'weight desc, source').
+func ParseOrder(s string) ([]Order, error) {
+	var orders []Order
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) > 2 || !isOrderField(strings.ToLower(fields[0])) {
+			return nil, fmt.Errorf("%w: invalid order '%s'", ErrInvalidQuery, strings.TrimSpace(part))
+		}
+
+		o := Order{Field: strings.ToLower(fields[0])}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+
+			case "desc":
+				o.Desc = true
+
+			default:
+				return nil, fmt.Errorf("%w: invalid order direction '%s'", ErrInvalidQuery, fields[1])
+			}
+		}
+		orders = append(orders, o)
+	}
+
+	return orders, nil
+}
+
+func (o Order) String() string {
+	if o.Desc {
+		return o.Field + " desc"
+	}
+	return o.Field + " asc"
+}
+
+// isNumericClause returns true if the clause type compares numbers when used
+// on properties.
+func isNumericClause(typ string) bool {
+	switch typ {
+	case "gt", "gte", "lt", "lte":
+		return true
+	}
+	return false
+}
+
+func isOrderField(field string) bool {
+	switch field {
+	case "source", "predicate", "target", "weight":
+		return true
+	}
+	return false
+}
+
+// sortTriples sorts the triples using the given keys.
+func sortTriples(triples []Triple, keys []Order) {
+	sort.Slice(triples, func(i, j int) bool {
+		return compareTriples(triples[i], triples[j], keys) < 0
+	})
+}
+
+// firstTriples selects the first k triples in the order of the keys from the
+// triples passed to add using a bounded heap. All the triples are selected if
+// k is not positive.
+type firstTriples struct {
+	k    int
+	heap tripleHeap
+}
+
+func newFirstTriples(k int, keys []Order) *firstTriples {
+	return &firstTriples{k: k, heap: tripleHeap{keys: keys, triples: []Triple{}}}
+}
+
+func (ft *firstTriples) add(tri Triple) {
+	h := &ft.heap
+	if ft.k <= 0 {
+		h.triples = append(h.triples, tri)
+	} else if h.Len() < ft.k {
+		heap.Push(h, tri)
+	} else if compareTriples(tri, h.triples[0], h.keys) < 0 {
+		h.triples[0] = tri
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted returns the selected triples in order.
+func (ft *firstTriples) sorted() []Triple {
+	sortTriples(ft.heap.triples, ft.heap.keys)
+	return ft.heap.triples
+}
+
+// tripleHeap is a max-heap of triples as per the keys.
+type tripleHeap struct {
+	keys    []Order
+	triples []Triple
+}
+
+func (h *tripleHeap) Len() int { return len(h.triples) }
+
+func (h *tripleHeap) Less(i, j int) bool {
+	return compareTriples(h.triples[i], h.triples[j], h.keys) > 0
+}
+
+func (h *tripleHeap) Swap(i, j int) { h.triples[i], h.triples[j] = h.triples[j], h.triples[i] }
+
+func (h *tripleHeap) Push(x interface{}) { h.triples = append(h.triples, x.(Triple)) }
+
+func (h *tripleHeap) Pop() interface{} {
+	last := h.triples[len(h.triples)-1]
+	h.triples = h.triples[:len(h.triples)-1]
+	return last
+}
+
+func compareTriples(a, b Triple, keys []Order) int {
+	for _, key := range keys {
+		c := 0
+		switch key.Field {
+		case "source":
+			c = strings.Compare(a.Source, b.Source)
+
+		case "predicate":
+			c = strings.Compare(a.Predicate, b.Predicate)
+
+		case "target":
+			c = strings.Compare(a.Target, b.Target)
+
+		case "weight":
+			if a.Weight < b.Weight {
+				c = -1
+			} else if a.Weight > b.Weight {
+				c = 1
+			}
+		}
+
+		if key.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
 }
 
 // Clause represents a query clause. Zero value of this struct will be used as
@@ -55,7 +305,10 @@ type Clause struct {
 	// gt, lt etc. Supported operations are dictated by store implementations.
 	Type string
 
-	// Value that should be used as the right operand for the operation.
+	// Value that should be used as the right operand for the operation. For
+	// in and not_in, Value is a comma separated list of values. For gt, gte,
+	// lt and lte on targets, Value is a number or a date which is compared
+	// with the values of literal targets (see Object).
 	Value string
 }
 
@@ -68,6 +321,15 @@ func (cl Clause) String() string {
 	return fmt.Sprintf("%s %s", cl.Type, cl.Value)
 }
 
+// list returns the comma separated values of an in or not_in clause.
+func (cl Clause) list() []string {
+	values := strings.Split(cl.Value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return values
+}
+
 func (cl *Clause) normalize() {
 	normalized, found := knownTypes[cl.Type]
 	if found {
@@ -88,4 +350,11 @@ var knownTypes = map[string]string{
 	">=":           "gte",
 	"~":            "like",
 	"~=":           "like",
+	"!=":           "neq",
+	"<>":           "neq",
+	"not-equal":    "neq",
+	"not in":       "not_in",
+	"!~":           "not like",
+	"not_like":     "not like",
+	"regexp":       "regex",
 }
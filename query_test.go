@@ -1,6 +1,8 @@
 package fabric_test
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -67,3 +69,251 @@ func TestQuery_Map(suite *testing.T) {
 		}
 	})
 }
+
+func TestParseOrder(suite *testing.T) {
+	suite.Parallel()
+
+	cases := []struct {
+		title     string
+		src       string
+		expected  []fabric.Order
+		expectErr bool
+	}{
+		{
+			title: "Empty",
+			src:   "",
+		},
+		{
+			title: "MultiKey",
+			src:   "weight desc, Source,target ASC",
+			expected: []fabric.Order{
+				{Field: "weight", Desc: true},
+				{Field: "source"},
+				{Field: "target"},
+			},
+		},
+		{
+			title:     "UnknownField",
+			src:       "name desc",
+			expectErr: true,
+		},
+		{
+			title:     "InvalidDirection",
+			src:       "weight down",
+			expectErr: true,
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			orders, err := fabric.ParseOrder(cs.src)
+			if err != nil {
+				if !cs.expectErr {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if cs.expectErr {
+				t.Fatalf("expecting error, got nil")
+			}
+
+			if !reflect.DeepEqual(cs.expected, orders) {
+				t.Errorf("expected %v, got %v", cs.expected, orders)
+			}
+		})
+	}
+}
+
+func TestQuery_OrderBy(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	triples := []fabric.Triple{
+		{Source: "b", Predicate: "p", Target: "x", Weight: 1},
+		{Source: "a", Predicate: "p", Target: "y", Weight: 2},
+		{Source: "c", Predicate: "p", Target: "x", Weight: 2},
+		{Source: "a", Predicate: "p", Target: "x", Weight: 3},
+	}
+
+	cases := []struct {
+		title    string
+		query    fabric.Query
+		expected []fabric.Triple
+	}{
+		{
+			title:    "Default",
+			query:    fabric.Query{Limit: 2},
+			expected: []fabric.Triple{triples[3], triples[1]},
+		},
+		{
+			title: "WeightDesc",
+			query: fabric.Query{
+				OrderBy: []fabric.Order{{Field: "weight", Desc: true}},
+			},
+			expected: []fabric.Triple{triples[3], triples[1], triples[2], triples[0]},
+		},
+		{
+			title: "MixedCaseField",
+			query: fabric.Query{
+				OrderBy: []fabric.Order{{Field: " Weight ", Desc: true}},
+				Limit:   1,
+			},
+			expected: []fabric.Triple{triples[3]},
+		},
+		{
+			title: "TargetThenSourceDesc",
+			query: fabric.Query{
+				Predicate: fabric.Clause{Type: "eq", Value: "p"},
+				OrderBy:   []fabric.Order{{Field: "target"}, {Field: "source", Desc: true}},
+				Limit:     3,
+			},
+			expected: []fabric.Triple{triples[2], triples[0], triples[3]},
+		},
+	}
+
+	for name, newStore := range stores {
+		fab := fabric.New(newStore())
+		if _, err := fab.InsertMany(context.Background(), triples); err != nil {
+			suite.Fatalf("failed to insert: %v", err)
+		}
+
+		suite.Run(name, func(t *testing.T) {
+			for _, cs := range cases {
+				t.Run(cs.title, func(t *testing.T) {
+					orderBy := append([]fabric.Order(nil), cs.query.OrderBy...)
+					got, err := fab.Query(context.Background(), cs.query)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					if !reflect.DeepEqual(cs.expected, got) {
+						t.Errorf("expected %v, got %v", cs.expected, got)
+					}
+
+					if !reflect.DeepEqual(orderBy, append([]fabric.Order(nil), cs.query.OrderBy...)) {
+						t.Errorf("expected order %v to be unchanged, got %v", orderBy, cs.query.OrderBy)
+					}
+				})
+			}
+
+			_, err := fab.Query(context.Background(), fabric.Query{OrderBy: []fabric.Order{{Field: "weight; DROP TABLE triples"}}})
+			if !errors.Is(err, fabric.ErrInvalidQuery) {
+				t.Errorf("expecting ErrInvalidQuery, got %v", err)
+			}
+		})
+	}
+}
+
+func TestQuery_Composition(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	triples := []fabric.Triple{
+		{Source: "Alice", Predicate: "knows", Target: "Bob", Weight: 1},
+		{Source: "Bob", Predicate: "knows", Target: "John", Weight: 2},
+		{Source: "Bob", Predicate: "likes", Target: "Alice", Weight: 3},
+		{Source: "John", Predicate: "hates", Target: "Bob", Weight: 4},
+	}
+
+	cases := []struct {
+		title    string
+		query    fabric.Query
+		expected []fabric.Triple
+	}{
+		{
+			title:    "NotEqual",
+			query:    fabric.Query{Target: fabric.Clause{Type: "!=", Value: "Bob"}},
+			expected: []fabric.Triple{triples[1], triples[2]},
+		},
+		{
+			title:    "In",
+			query:    fabric.Query{Predicate: fabric.Clause{Type: "in", Value: "knows, likes"}},
+			expected: []fabric.Triple{triples[0], triples[1], triples[2]},
+		},
+		{
+			title:    "NotIn",
+			query:    fabric.Query{Weight: fabric.Clause{Type: "not_in", Value: "1,3"}},
+			expected: []fabric.Triple{triples[1], triples[3]},
+		},
+		{
+			title:    "NotLike",
+			query:    fabric.Query{Source: fabric.Clause{Type: "not like", Value: "B*"}},
+			expected: []fabric.Triple{triples[0], triples[3]},
+		},
+		{
+			title: "Or",
+			query: fabric.Query{
+				Or: []fabric.Query{
+					{Predicate: fabric.Clause{Type: "eq", Value: "likes"}},
+					{Source: fabric.Clause{Type: "eq", Value: "John"}},
+				},
+			},
+			expected: []fabric.Triple{triples[2], triples[3]},
+		},
+		{
+			title: "OrWithClauses",
+			query: fabric.Query{
+				Source: fabric.Clause{Type: "eq", Value: "Bob"},
+				Or: []fabric.Query{
+					{Target: fabric.Clause{Type: "eq", Value: "Alice"}},
+					{Weight: fabric.Clause{Type: "gt", Value: "3"}},
+				},
+			},
+			expected: []fabric.Triple{triples[2]},
+		},
+		{
+			title: "Not",
+			query: fabric.Query{
+				Predicate: fabric.Clause{Type: "neq", Value: "hates"},
+				Not: &fabric.Query{
+					Source: fabric.Clause{Type: "eq", Value: "Bob"},
+					Weight: fabric.Clause{Type: "lt", Value: "3"},
+				},
+			},
+			expected: []fabric.Triple{triples[0], triples[2]},
+		},
+		{
+			title:    "NotAny",
+			query:    fabric.Query{Not: &fabric.Query{}},
+			expected: []fabric.Triple{},
+		},
+	}
+
+	for name, newStore := range stores {
+		fab := fabric.New(newStore())
+		insert(suite, fab, triples...)
+
+		suite.Run(name, func(t *testing.T) {
+			for _, cs := range cases {
+				t.Run(cs.title, func(t *testing.T) {
+					got, err := fab.Query(context.Background(), cs.query)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					if len(cs.expected) == 0 && len(got) == 0 {
+						return
+					}
+
+					if !reflect.DeepEqual(cs.expected, got) {
+						t.Errorf("expected %v, got %v", cs.expected, got)
+					}
+				})
+			}
+
+			_, err := fab.Query(context.Background(), fabric.Query{
+				Or: []fabric.Query{{Weight: fabric.Clause{Type: "in", Value: "1,x"}}},
+			})
+			if !errors.Is(err, fabric.ErrInvalidClause) {
+				t.Errorf("expecting ErrInvalidClause, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package fabric
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Namespaces used when mapping triples to RDF.
+const (
+	// DefaultBase is the base IRI used for names that are not IRIs when
+	// RDFOptions.Base is not set.
+	DefaultBase = "urn:fabric:"
+
+	// XSD is the namespace of the XML schema datatypes of RDF literals.
+	XSD = "http://www.w3.org/2001/XMLSchema#"
+
+	// RDF is the namespace of the RDF vocabulary.
+	RDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+)
+
+// importBatchSize is the number of triples read before inserting them into
+// the store while importing.
+const importBatchSize = 1000
+
+// RDFOptions configures the mapping between triples and RDF statements.
+//
+// Source, predicate and (entity) target names that are absolute IRIs (e.g.,
+// 'http://xmlns.com/foaf/0.1/knows') are written as is, names starting with
+// '_:' are written as blank nodes and all the other names are written as IRIs
+// relative to Base (e.g., 'Bob' becomes <urn:fabric:Bob>). While reading, the
+// reverse mapping is applied except that blank node labels are scoped to the
+// document using BlankNodePrefix. Literal targets (see Object) are written as RDF
+// literals using the XSD datatypes. Weights and properties are not part of
+// RDF and are not exported.
+type RDFOptions struct {
+	// Base is the IRI prefix for names that are not IRIs. Defaults to
+	// DefaultBase.
+	Base string
+
+	// Prefixes maps prefix names to namespace IRIs. Used for abbreviating
+	// IRIs when writing Turtle.
+	Prefixes map[string]string
+
+	// BlankNodePrefix is prepended to the labels of the blank nodes read
+	// from a document (e.g., '_:b1' becomes '_:<prefix>b1') so that the
+	// blank nodes of separate documents are distinct. It should consist of
+	// letters, digits and '_'. Defaults to a random prefix for every reader.
+	BlankNodePrefix string
+}
+
+// newBlankNodePrefix returns a random prefix for blank node labels.
+func newBlankNodePrefix() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("failed to generate blank node prefix: %v", err))
+	}
+	return "b" + hex.EncodeToString(buf) + "_"
+}
+
+// ImportNTriples reads N-Triples statements from r and inserts them into the
+// fabric in batches. Statements that could not be inserted are reported in
+// the result along with their index (0 based) in the input. Returns error if
+// the input is not valid N-Triples.
+func (f *Fabric) ImportNTriples(ctx context.Context, r io.Reader, opts RDFOptions) (BatchResult, error) {
+	return f.importTriples(ctx, NewNTriplesReader(r, opts))
+}
+
+// ExportNTriples writes the triples matching the query to w as N-Triples.
+func (f *Fabric) ExportNTriples(ctx context.Context, w io.Writer, query Query, opts RDFOptions) error {
+	return f.exportTriples(ctx, NewNTriplesWriter(w, opts), query)
+}
+
+// ImportTurtle reads Turtle statements from r and inserts them into the
+// fabric in batches. See ImportNTriples.
+func (f *Fabric) ImportTurtle(ctx context.Context, r io.Reader, opts RDFOptions) (BatchResult, error) {
+	return f.importTriples(ctx, NewTurtleReader(r, opts))
+}
+
+// ExportTurtle writes the triples matching the query to w as Turtle.
+func (f *Fabric) ExportTurtle(ctx context.Context, w io.Writer, query Query, opts RDFOptions) error {
+	return f.exportTriples(ctx, NewTurtleWriter(w, opts), query)
+}
+
+type tripleReader interface {
+	Read() (Triple, error)
+}
+
+type tripleWriter interface {
+	Write(tri Triple) error
+	Flush() error
+}
+
+// importTriples inserts the triples read from rd in batches. Read errors of
+// type LineError are reported as failures of the record while the other
+// errors stop the import. If rd reports the line numbers of the records,
+// insert failures are reported with the line numbers as well.
+func (f *Fabric) importTriples(ctx context.Context, rd tripleReader) (BatchResult, error) {
+	var res BatchResult
+	lr, hasLines := rd.(interface{ Line() int })
+
+	var batch []Triple
+	var indices, lines []int
+	insert := func() error {
+		br, err := f.InsertMany(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		res.Inserted += br.Inserted
+		for _, be := range br.Failed {
+			if hasLines {
+				be.Err = &LineError{Line: lines[be.Index], Err: be.Err}
+			}
+			be.Index = indices[be.Index]
+			res.Failed = append(res.Failed, be)
+		}
+
+		batch, indices, lines = batch[:0], indices[:0], lines[:0]
+		return nil
+	}
+
+	for index := 0; ; index++ {
+		tri, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var le *LineError
+		if errors.As(err, &le) {
+			res.Failed = append(res.Failed, BatchError{Index: index, Err: err})
+			continue
+		} else if err != nil {
+			return res, err
+		}
+
+		batch = append(batch, tri)
+		indices = append(indices, index)
+		if hasLines {
+			lines = append(lines, lr.Line())
+		}
+
+		if len(batch) == importBatchSize {
+			if err := insert(); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := insert(); err != nil {
+			return res, err
+		}
+	}
+
+	sort.Slice(res.Failed, func(i, j int) bool {
+		return res.Failed[i].Index < res.Failed[j].Index
+	})
+	return res, nil
+}
+
+func (f *Fabric) exportTriples(ctx context.Context, wr tripleWriter, query Query) error {
+	if err := f.Iterate(ctx, query, wr.Write); err != nil {
+		return err
+	}
+	return wr.Flush()
+}
+
+func (opts RDFOptions) base() string {
+	if opts.Base == "" {
+		return DefaultBase
+	}
+	return opts.Base
+}
+
+// iri returns the IRI for the name.
+func (opts RDFOptions) iri(name string) string {
+	if isAbsoluteIRI(name) {
+		return escapeIRI(name, false)
+	}
+	return opts.base() + escapeIRI(name, true)
+}
+
+// name returns the name for the IRI.
+func (opts RDFOptions) name(iri string) string {
+	base := opts.base()
+	if !strings.HasPrefix(iri, base) || len(iri) == len(base) {
+		return iri
+	}
+
+	name, err := url.PathUnescape(iri[len(base):])
+	if err != nil {
+		return iri[len(base):]
+	}
+	return name
+}
+
+// isAbsoluteIRI returns true if s starts with an IRI scheme.
+func isAbsoluteIRI(s string) bool {
+	i := strings.IndexByte(s, ':')
+	if i <= 0 || i == len(s)-1 {
+		return false
+	}
+
+	for j, c := range s[:i] {
+		isAlpha := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !isAlpha && (j == 0 || !((c >= '0' && c <= '9') || c == '+' || c == '-' || c == '.')) {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeIRI percent-encodes the characters that are not allowed in IRIs.
+// If name is true, '%' is encoded as well so that the name can be decoded.
+func escapeIRI(s string, name bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 0x20 || strings.IndexByte(`<>"{}|^`+"`"+`\`, c) >= 0 || (name && c == '%') {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// xsdTypes maps XSD datatypes onto the datatypes of literal objects.
+var xsdTypes = map[string]Datatype{
+	"string":             String,
+	"integer":            Int,
+	"int":                Int,
+	"long":               Int,
+	"short":              Int,
+	"byte":               Int,
+	"nonNegativeInteger": Int,
+	"nonPositiveInteger": Int,
+	"positiveInteger":    Int,
+	"negativeInteger":    Int,
+	"unsignedLong":       Int,
+	"unsignedInt":        Int,
+	"unsignedShort":      Int,
+	"unsignedByte":       Int,
+	"decimal":            Float,
+	"double":             Float,
+	"float":              Float,
+	"boolean":            Bool,
+	"date":               Date,
+}
+
+// xsdNames maps the datatypes of literal objects onto XSD datatypes.
+var xsdNames = map[Datatype]string{
+	Int:   "integer",
+	Float: "double",
+	Bool:  "boolean",
+	Date:  "date",
+}
+
+// rdfTerm is a subject, predicate or object of an RDF statement.
+type rdfTerm struct {
+	kind     int
+	value    string
+	datatype string
+	lang     string
+}
+
+// Kinds of RDF terms.
+const (
+	termIRI = iota
+	termBlank
+	termLiteral
+)
+
+// termOf returns the RDF term for the triple name or target.
+func (opts RDFOptions) termOf(name string, target bool) (rdfTerm, error) {
+	if strings.HasPrefix(name, "_:") {
+		return rdfTerm{kind: termBlank, value: name[2:]}, nil
+	}
+
+	if target {
+		obj, err := ParseObject(name)
+		if err != nil {
+			return rdfTerm{}, err
+		}
+
+		if obj.Literal {
+			term := rdfTerm{kind: termLiteral, value: obj.Value, lang: obj.Lang}
+			if xsd, found := xsdNames[obj.Datatype]; found {
+				term.datatype = XSD + xsd
+			}
+			return term, nil
+		}
+	}
+
+	return rdfTerm{kind: termIRI, value: opts.iri(name)}, nil
+}
+
+// terms returns the RDF terms for the source and the target of the triple.
+func (opts RDFOptions) terms(tri Triple) (rdfTerm, rdfTerm, error) {
+	subject, err := opts.termOf(tri.Source, false)
+	if err != nil {
+		return rdfTerm{}, rdfTerm{}, err
+	}
+
+	object, err := opts.termOf(tri.Target, true)
+	if err != nil {
+		return rdfTerm{}, rdfTerm{}, err
+	}
+
+	return subject, object, nil
+}
+
+// nameOf returns the triple name or target for the RDF term.
+func (opts RDFOptions) nameOf(term rdfTerm) (string, error) {
+	switch term.kind {
+	case termBlank:
+		return "_:" + opts.BlankNodePrefix + term.value, nil
+
+	case termIRI:
+		return opts.name(term.value), nil
+	}
+
+	if term.lang != "" {
+		return LangLiteral(term.value, term.lang).String(), nil
+	}
+
+	dt := String
+	if term.datatype != "" && term.datatype != RDF+"langString" {
+		var found bool
+		if dt, found = xsdTypes[strings.TrimPrefix(term.datatype, XSD)]; !found || !strings.HasPrefix(term.datatype, XSD) {
+			return "", fmt.Errorf("unsupported datatype <%s>", term.datatype)
+		}
+	}
+
+	obj, err := Literal(term.value, dt).canonical()
+	if err != nil {
+		return "", err
+	}
+	return obj.String(), nil
+}
+
+// toTriple maps the RDF statement onto a triple.
+func (opts RDFOptions) toTriple(subject, predicate, object rdfTerm) (Triple, error) {
+	var tri Triple
+	var err error
+
+	if tri.Source, err = opts.nameOf(subject); err != nil {
+		return tri, err
+	}
+
+	if tri.Predicate, err = opts.nameOf(predicate); err != nil {
+		return tri, err
+	}
+
+	tri.Target, err = opts.nameOf(object)
+	return tri, err
+}
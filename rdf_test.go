@@ -0,0 +1,281 @@
+package fabric_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+var rdfTriples = []fabric.Triple{
+	{Source: "Bob", Predicate: "age", Target: `"42"^^int`},
+	{Source: "Bob", Predicate: "born", Target: `"1980-02-29"^^date`},
+	{Source: "Bob", Predicate: "height", Target: `"1.72"^^float`},
+	{Source: "Bob", Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", Target: "Person"},
+	{Source: "Bob", Predicate: "knows", Target: "_:b1"},
+	{Source: "Bob", Predicate: "name", Target: `"Bob \"The Builder\"\nSmith"@en`},
+	{Source: "Bob", Predicate: "verified", Target: `"true"^^bool`},
+	{Source: "Dave%1", Predicate: "knows", Target: "http://example.org/people/Alice"},
+	{Source: "_:b1", Predicate: "nick", Target: `"Robby"`},
+}
+
+// scopeBlankNodes returns the triples with the prefix added to the labels of
+// the blank nodes as done while importing.
+func scopeBlankNodes(triples []fabric.Triple, prefix string) []fabric.Triple {
+	var res []fabric.Triple
+	for _, tri := range triples {
+		if strings.HasPrefix(tri.Source, "_:") {
+			tri.Source = "_:" + prefix + tri.Source[2:]
+		}
+		if strings.HasPrefix(tri.Target, "_:") {
+			tri.Target = "_:" + prefix + tri.Target[2:]
+		}
+		res = append(res, tri)
+	}
+	return res
+}
+
+func TestFabric_NTriples(suite *testing.T) {
+	suite.Parallel()
+
+	suite.Run("RoundTrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		fab := fabric.New(&fabric.InMemoryStore{})
+		insert(t, fab, rdfTriples...)
+
+		if err := fab.ExportNTriples(context.Background(), &buf, fabric.Query{}, fabric.RDFOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := `<urn:fabric:Bob> <urn:fabric:age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .` + "\n"
+		if !strings.HasPrefix(buf.String(), expected) {
+			t.Errorf("expected output to start with '%s', got '%s'", expected, buf.String())
+		}
+
+		imported := fabric.New(&fabric.InMemoryStore{})
+		res, err := imported.ImportNTriples(context.Background(), &buf, fabric.RDFOptions{BlankNodePrefix: "i_"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Inserted != len(rdfTriples) || len(res.Failed) != 0 {
+			t.Errorf("expected %d inserts, got %+v", len(rdfTriples), res)
+		}
+
+		assertTriples(t, imported, scopeBlankNodes(rdfTriples, "i_"))
+	})
+
+	suite.Run("Import", func(t *testing.T) {
+		doc := `# comment
+<http://example.org/Bob> <http://example.org/knows> <http://example.org/Alice> . # trailing comment
+
+_:x <http://example.org/age> "7"^^<http://www.w3.org/2001/XMLSchema#nonNegativeInteger> .
+<http://example.org/Bob> <http://example.org/name> "Bobé"@EN-us .
+<http://example.org/Bob> <http://example.org/knows> <http://example.org/Alice> .
+`
+		fab := fabric.New(&fabric.InMemoryStore{})
+		res, err := fab.ImportNTriples(context.Background(), strings.NewReader(doc), fabric.RDFOptions{
+			Base:            "http://example.org/",
+			BlankNodePrefix: "d_",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if res.Inserted != 3 || len(res.Failed) != 1 || res.Failed[0].Index != 3 {
+			t.Errorf("expected 3 inserts and a conflict at 3, got %+v", res)
+		}
+
+		assertTriples(t, fab, []fabric.Triple{
+			{Source: "Bob", Predicate: "knows", Target: "Alice"},
+			{Source: "Bob", Predicate: "name", Target: `"Bobé"@en-us`},
+			{Source: "_:d_x", Predicate: "age", Target: `"7"^^int`},
+		})
+	})
+
+	suite.Run("InvalidLiterals", func(t *testing.T) {
+		doc := `<a:s> <a:p> "o"^^<a:custom> .
+<a:s> <a:p> "x"^^<http://www.w3.org/2001/XMLSchema#int> .
+<a:s> <a:p> <a:o> .
+`
+		res, err := fabric.New(&fabric.InMemoryStore{}).ImportNTriples(context.Background(), strings.NewReader(doc), fabric.RDFOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if res.Inserted != 1 || len(res.Failed) != 2 {
+			t.Fatalf("expected 1 insert and 2 failures, got %+v", res)
+		}
+
+		for i, msg := range []string{"line 1: unsupported datatype", "line 2: invalid int literal"} {
+			var le *fabric.LineError
+			if !errors.As(res.Failed[i].Err, &le) || !strings.Contains(le.Error(), msg) {
+				t.Errorf("expecting LineError containing '%s', got %v", msg, res.Failed[i].Err)
+			}
+		}
+	})
+
+	suite.Run("Errors", func(t *testing.T) {
+		cases := map[string]string{
+			"line 2: expecting '.'":        "<a:s> <a:p> <a:o> .\n<a:s> <a:p> <a:o>",
+			"line 1: unexpected 'e'":       "ex:s <a:p> <a:o> .\n",
+			"line 1: unterminated string":  "<a:s> <a:p> \"o .\n",
+			"line 1: expecting a subject":  "\"s\" <a:p> <a:o> .\n",
+			"line 1: expecting an object":  "<a:s> <a:p> .\n",
+			"line 3: invalid character":    "\n\n<a:s> <a:p> <a:o .\n",
+			"line 1: unterminated IRI":     "<a:s> <a:p> <a:o",
+			"line 1: expecting a predicat": "<a:s> _:p <a:o> .\n",
+		}
+
+		for msg, doc := range cases {
+			_, err := fabric.New(&fabric.InMemoryStore{}).ImportNTriples(context.Background(), strings.NewReader(doc), fabric.RDFOptions{})
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("expecting error containing '%s' for %q, got %v", msg, doc, err)
+			}
+		}
+	})
+}
+
+func TestFabric_Turtle(suite *testing.T) {
+	suite.Parallel()
+
+	suite.Run("RoundTrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		fab := fabric.New(&fabric.InMemoryStore{})
+		insert(t, fab, rdfTriples...)
+
+		opts := fabric.RDFOptions{Prefixes: map[string]string{"people": "http://example.org/people/"}}
+		if err := fab.ExportTurtle(context.Background(), &buf, fabric.Query{}, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, s := range []string{
+			"@prefix : <urn:fabric:> .\n",
+			"@prefix people: <http://example.org/people/> .\n",
+			":Bob :age 42 ;\n    :born \"1980-02-29\"^^xsd:date ;",
+			"    a :Person ;\n",
+			"<urn:fabric:Dave%251> :knows people:Alice .\n",
+		} {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("expected output to contain '%s', got:\n%s", s, buf.String())
+			}
+		}
+
+		imported := fabric.New(&fabric.InMemoryStore{})
+		opts.BlankNodePrefix = "i_"
+		res, err := imported.ImportTurtle(context.Background(), &buf, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Inserted != len(rdfTriples) {
+			t.Errorf("expected %d inserts, got %+v", len(rdfTriples), res)
+		}
+
+		assertTriples(t, imported, scopeBlankNodes(rdfTriples, "i_"))
+	})
+
+	suite.Run("Import", func(t *testing.T) {
+		doc := `@base <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+PREFIX ex: <people/>
+
+ex:bob a foaf:Person ;
+    foaf:knows ex:alice, ex:carol ;
+    foaf:age 42 ;
+    foaf:height 1.8 ;
+    foaf:weight 7.5e1 ;
+    foaf:active true ;
+    foaf:title '''Dr.
+"Bob"''' ;
+    foaf:account [ foaf:nick "bobby"@en ] .
+
+<people/alice> foaf:knows <#carol> .
+[ foaf:name "anon" ] .
+`
+		fab := fabric.New(&fabric.InMemoryStore{})
+		res, err := fab.ImportTurtle(context.Background(), strings.NewReader(doc), fabric.RDFOptions{
+			Base:            "http://example.org/people/",
+			BlankNodePrefix: "d_",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Inserted != 12 || len(res.Failed) != 0 {
+			t.Errorf("expected 12 inserts, got %+v", res)
+		}
+
+		got, err := fab.Query(context.Background(), fabric.Query{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []fabric.Triple{
+			{Source: "_:d_anon1", Predicate: "http://xmlns.com/foaf/0.1/nick", Target: `"bobby"@en`},
+			{Source: "_:d_anon2", Predicate: "http://xmlns.com/foaf/0.1/name", Target: `"anon"`},
+			{Source: "alice", Predicate: "http://xmlns.com/foaf/0.1/knows", Target: "http://example.org/#carol"},
+			{Source: "bob", Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", Target: "http://xmlns.com/foaf/0.1/Person"},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/account", Target: "_:d_anon1"},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/active", Target: `"true"^^bool`},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/age", Target: `"42"^^int`},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/height", Target: `"1.8"^^float`},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/knows", Target: "alice"},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/knows", Target: "carol"},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/title", Target: `"Dr.\n\"Bob\""`},
+			{Source: "bob", Predicate: "http://xmlns.com/foaf/0.1/weight", Target: `"75"^^float`},
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("expected:\n%v\ngot:\n%v", expected, got)
+		}
+	})
+
+	suite.Run("SeparateImports", func(t *testing.T) {
+		ctx := context.Background()
+		fab := fabric.New(&fabric.InMemoryStore{})
+		for _, doc := range []string{
+			`<Alice> <knows> [ <name> "A1" ] .`,
+			`<Carol> <knows> [ <name> "C1" ] .`,
+		} {
+			if _, err := fab.ImportTurtle(ctx, strings.NewReader(doc), fabric.RDFOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		known := map[string]string{}
+		for _, person := range []string{"Alice", "Carol"} {
+			triples, err := fab.Query(ctx, fabric.Query{Source: fabric.Clause{Type: "eq", Value: person}})
+			if err != nil || len(triples) != 1 {
+				t.Fatalf("expected one triple for %s, got %v (err=%v)", person, triples, err)
+			}
+			known[person] = triples[0].Target
+
+			names, err := fab.Count(ctx, fabric.Query{Source: fabric.Clause{Type: "eq", Value: triples[0].Target}})
+			if err != nil || names != 1 {
+				t.Errorf("expected one name for the node known by %s, got %d (err=%v)", person, names, err)
+			}
+		}
+
+		if known["Alice"] == known["Carol"] {
+			t.Errorf("expected separate blank nodes, got '%s' for both", known["Alice"])
+		}
+	})
+
+	suite.Run("Errors", func(t *testing.T) {
+		cases := map[string]string{
+			"line 1: undefined prefix 'ex'":           "ex:s ex:p ex:o .\n",
+			"line 2: collections are not supported":   "@prefix ex: <http://example.org/> .\nex:s ex:p (1 2) .\n",
+			"line 1: expecting a prefix name":         "@prefix <http://example.org/> .\n",
+			"line 2: expecting ']'":                   "@prefix ex: <http://example.org/> .\nex:s ex:p [ ex:q ex:r .\n",
+			"line 1: expecting a predicate, got '42'": "<a:s> 42 <a:o> .\n",
+		}
+
+		for msg, doc := range cases {
+			_, err := fabric.New(&fabric.InMemoryStore{}).ImportTurtle(context.Background(), strings.NewReader(doc), fabric.RDFOptions{})
+			if err == nil || !strings.Contains(err.Error(), msg) {
+				t.Errorf("expecting error containing '%s' for %q, got %v", msg, doc, err)
+			}
+		}
+	})
+}
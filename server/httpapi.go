@@ -2,10 +2,17 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/spy16/fabric"
 )
@@ -15,8 +22,14 @@ import (
 func NewHTTP(fab *fabric.Fabric) http.Handler {
 	handleQuery := queryHandler(fab)
 	handleInsert := insertHandler(fab)
+	handleUpsert := upsertHandler(fab)
 	handleReWeight := reweightHandler(fab)
 	handleDelete := deleteHandler(fab)
+	handleFQL := fqlHandler(fab)
+	handlePaths := pathsHandler(fab)
+	handleNeighborhood := neighborhoodHandler(fab)
+	handleWatch := watchHandler(fab)
+	handleImport := importHandler(fab)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/triples", func(wr http.ResponseWriter, req *http.Request) {
@@ -27,6 +40,9 @@ func NewHTTP(fab *fabric.Fabric) http.Handler {
 		case http.MethodPost:
 			handleInsert(wr, req)
 
+		case http.MethodPut:
+			handleUpsert(wr, req)
+
 		case http.MethodPatch:
 			handleReWeight(wr, req)
 
@@ -39,6 +55,57 @@ func NewHTTP(fab *fabric.Fabric) http.Handler {
 			})
 		}
 	})
+	mux.HandleFunc("/triples/neighborhood", func(wr http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeResponse(wr, req, http.StatusMethodNotAllowed, map[string]string{
+				"error": "method not allowed",
+			})
+			return
+		}
+
+		handleNeighborhood(wr, req)
+	})
+	mux.HandleFunc("/triples/watch", func(wr http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeResponse(wr, req, http.StatusMethodNotAllowed, map[string]string{
+				"error": "method not allowed",
+			})
+			return
+		}
+
+		handleWatch(wr, req)
+	})
+	mux.HandleFunc("/triples/import", func(wr http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			writeResponse(wr, req, http.StatusMethodNotAllowed, map[string]string{
+				"error": "method not allowed",
+			})
+			return
+		}
+
+		handleImport(wr, req)
+	})
+	mux.HandleFunc("/fql", func(wr http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodPost:
+			handleFQL(wr, req)
+
+		default:
+			writeResponse(wr, req, http.StatusMethodNotAllowed, map[string]string{
+				"error": "method not allowed",
+			})
+		}
+	})
+	mux.HandleFunc("/paths", func(wr http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeResponse(wr, req, http.StatusMethodNotAllowed, map[string]string{
+				"error": "method not allowed",
+			})
+			return
+		}
+
+		handlePaths(wr, req)
+	})
 	return withLogs(mux)
 }
 
@@ -46,21 +113,190 @@ func queryHandler(fab *fabric.Fabric) http.HandlerFunc {
 	return func(wr http.ResponseWriter, req *http.Request) {
 		query, err := readQuery(req.URL.Query())
 		if err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		// paginated requests get the page with the next cursor set in the
+		// X-Next-Cursor header while the rest get the list of triples
+		// streamed as they are read.
+		paginated := query.Limit > 0 || query.Cursor != ""
+		if format := outputFormat(req); !paginated && (format == "json" || format == "ndjson") {
+			streamTriples(wr, req, fab, *query, format == "ndjson")
+			return
+		}
+
+		page, err := fab.QueryPage(req.Context(), *query)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		if page.Next != "" {
+			wr.Header().Set("X-Next-Cursor", page.Next)
+		}
+
+		if page.Triples == nil {
+			page.Triples = []fabric.Triple{}
+		}
+
+		writeTriples(wr, req, http.StatusOK, page.Triples)
+	}
+}
+
+func fqlHandler(fab *fabric.Fabric) http.HandlerFunc {
+	return func(wr http.ResponseWriter, req *http.Request) {
+		src := req.URL.Query().Get("q")
+		if req.Method == http.MethodPost {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				writeError(wr, req, http.StatusBadRequest, err)
+				return
+			}
+			src = string(body)
+		}
+
+		bindings, err := fab.QueryFQL(req.Context(), src)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(wr, req, http.StatusOK, bindings)
+	}
+}
+
+func pathsHandler(fab *fabric.Fabric) http.HandlerFunc {
+	return func(wr http.ResponseWriter, req *http.Request) {
+		vals := req.URL.Query()
+
+		opts, err := readPathOptions(vals)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
 			return
 		}
 
-		tri, err := fab.Query(req.Context(), *query)
+		path, err := fab.ShortestPath(req.Context(), vals.Get("from"), vals.Get("to"), *opts)
 		if err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		writeTriples(wr, req, http.StatusOK, path)
+	}
+}
+
+func neighborhoodHandler(fab *fabric.Fabric) http.HandlerFunc {
+	return func(wr http.ResponseWriter, req *http.Request) {
+		vals := req.URL.Query()
+
+		opts, err := readTraverseOptions(vals)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		triples, err := fab.Traverse(req.Context(), vals.Get("start"), *opts)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		writeTriples(wr, req, http.StatusOK, triples)
+	}
+}
+
+// watchHandler streams the change events for triples matching the query as
+// Server-Sent Events until the client disconnects.
+func watchHandler(fab *fabric.Fabric) http.HandlerFunc {
+	return func(wr http.ResponseWriter, req *http.Request) {
+		flusher, ok := wr.(http.Flusher)
+		if !ok {
+			writeError(wr, req, http.StatusInternalServerError, errors.New("streaming not supported"))
+			return
+		}
+
+		query, err := readQuery(req.URL.Query())
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		events, err := fab.Watch(req.Context(), *query)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		wr.Header().Set("Content-Type", "text/event-stream")
+		wr.Header().Set("Cache-Control", "no-cache")
+		wr.Header().Set("Connection", "keep-alive")
+		wr.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for ev := range events {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				log.Printf("failed to marshal event: %v", err)
+				continue
+			}
+
+			fmt.Fprintf(wr, "event: %s\ndata: %s\n\n", ev.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// importHandler inserts the triples in the request body which can be CSV,
+// TSV, N-Triples or Turtle as selected by the format parameter or the
+// content type of the request.
+func importHandler(fab *fabric.Fabric) http.HandlerFunc {
+	return func(wr http.ResponseWriter, req *http.Request) {
+		vals := req.URL.Query()
+
+		var res fabric.BatchResult
+		var err error
+		switch format := importFormat(req); format {
+		case "csv", "tsv":
+			opts := fabric.CSVOptions{
+				SourceColumn:    vals.Get("source_col"),
+				PredicateColumn: vals.Get("predicate_col"),
+				TargetColumn:    vals.Get("target_col"),
+				WeightColumn:    vals.Get("weight_col"),
+				Predicate:       vals.Get("predicate"),
+			}
+			if format == "tsv" {
+				opts.Comma = '\t'
+			}
+
+			if opts.Header, err = readBool(vals, "header"); err != nil {
+				writeError(wr, req, http.StatusBadRequest, err)
+				return
+			}
+
+			res, err = fab.ImportCSV(req.Context(), req.Body, opts)
+
+		case "nt":
+			res, err = fab.ImportNTriples(req.Context(), req.Body, fabric.RDFOptions{Base: vals.Get("base")})
+
+		case "ttl":
+			res, err = fab.ImportTurtle(req.Context(), req.Body, fabric.RDFOptions{Base: vals.Get("base")})
+
+		default:
+			writeError(wr, req, http.StatusBadRequest, fmt.Errorf("unsupported import format '%s'", format))
+			return
+		}
+
+		if err != nil {
+			writeResponse(wr, req, http.StatusBadRequest, map[string]interface{}{
+				"error":    err.Error(),
+				"inserted": res.Inserted,
+				"failed":   res.Failed,
 			})
 			return
 		}
 
-		writeTriples(wr, req, http.StatusOK, tri)
+		writeResponse(wr, req, http.StatusOK, res)
 	}
 }
 
@@ -68,26 +304,40 @@ func insertHandler(fab *fabric.Fabric) http.HandlerFunc {
 	return func(wr http.ResponseWriter, req *http.Request) {
 		var tri fabric.Triple
 		if err := json.NewDecoder(req.Body).Decode(&tri); err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
+			writeError(wr, req, http.StatusBadRequest, err)
 			return
 		}
 
-		if err := tri.Validate(); err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
+		if err := fab.Insert(req.Context(), tri); err != nil {
+			writeError(wr, req, http.StatusInternalServerError, err)
 			return
 		}
+		writeResponse(wr, req, http.StatusCreated, nil)
+	}
+}
 
-		if err := fab.Insert(req.Context(), tri); err != nil {
-			writeResponse(wr, req, http.StatusInternalServerError, map[string]string{
-				"error": err.Error(),
-			})
+func upsertHandler(fab *fabric.Fabric) http.HandlerFunc {
+	return func(wr http.ResponseWriter, req *http.Request) {
+		policy := fabric.ReplaceWeight
+		if s := req.URL.Query().Get("on_conflict"); s != "" {
+			var err error
+			if policy, err = fabric.ParseConflictPolicy(s); err != nil {
+				writeError(wr, req, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		var tri fabric.Triple
+		if err := json.NewDecoder(req.Body).Decode(&tri); err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
 			return
 		}
-		writeResponse(wr, req, http.StatusCreated, nil)
+
+		if err := fab.Upsert(req.Context(), tri, policy); err != nil {
+			writeError(wr, req, http.StatusInternalServerError, err)
+			return
+		}
+		writeResponse(wr, req, http.StatusOK, nil)
 	}
 }
 
@@ -100,17 +350,13 @@ func reweightHandler(fab *fabric.Fabric) http.HandlerFunc {
 			Replace bool    `json:"replace"`
 		}
 		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
+			writeError(wr, req, http.StatusBadRequest, err)
 			return
 		}
 
 		updates, err := fab.ReWeight(req.Context(), payload.Query, payload.Delta, payload.Replace)
 		if err != nil {
-			writeResponse(wr, req, http.StatusInternalServerError, map[string]string{
-				"error": err.Error(),
-			})
+			writeError(wr, req, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -124,17 +370,13 @@ func deleteHandler(fab *fabric.Fabric) http.HandlerFunc {
 	return func(wr http.ResponseWriter, req *http.Request) {
 		query, err := readQuery(req.URL.Query())
 		if err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
+			writeError(wr, req, http.StatusBadRequest, err)
 			return
 		}
 
 		deleted, err := fab.Delete(req.Context(), *query)
 		if err != nil {
-			writeResponse(wr, req, http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
+			writeError(wr, req, http.StatusBadRequest, err)
 			return
 		}
 
@@ -144,14 +386,121 @@ func deleteHandler(fab *fabric.Fabric) http.HandlerFunc {
 	}
 }
 
+// streamTriples writes the triples matching the query as they are read from
+// the store, either as a JSON array or as newline delimited JSON. Errors after
+// the first triple is written cannot be reported to the client since the
+// status is already sent. Such errors are logged and the response is cut
+// short, leaving an incomplete JSON array.
+func streamTriples(wr http.ResponseWriter, req *http.Request, fab *fabric.Fabric, query fabric.Query, ndjson bool) {
+	started := false
+	start := func() {
+		started = true
+		if ndjson {
+			wr.Header().Set("Content-Type", "application/x-ndjson")
+		} else {
+			wr.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+		wr.WriteHeader(http.StatusOK)
+
+		if !ndjson {
+			io.WriteString(wr, "[")
+		}
+	}
+
+	err := fab.Iterate(req.Context(), query, func(tri fabric.Triple) error {
+		data, err := json.Marshal(tri)
+		if err != nil {
+			return err
+		}
+
+		if !started {
+			start()
+		} else if !ndjson {
+			data = append([]byte(","), data...)
+		}
+
+		if ndjson {
+			data = append(data, '\n')
+		}
+
+		_, err = wr.Write(data)
+		return err
+	})
+	if err != nil {
+		if !started {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		log.Printf("failed to stream triples: %v", err)
+		return
+	}
+
+	if !started {
+		start()
+	}
+
+	if !ndjson {
+		io.WriteString(wr, "]\n")
+	}
+}
+
 func writeTriples(wr http.ResponseWriter, req *http.Request, status int, triples []fabric.Triple) {
 	switch outputFormat(req) {
 	case "dot":
-		wr.Write([]byte(fabric.ExportDOT("fabric", triples)))
+		opts, err := readDOTOptions(req.URL.Query(), triples)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		wr.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		wr.WriteHeader(status)
+		if err := writeDOT(wr, triples, *opts); err != nil {
+			log.Printf("failed to write dot: %v", err)
+		}
+
+	case "graphml":
+		wr.Header().Set("Content-Type", "application/graphml+xml")
+		wr.WriteHeader(status)
+		io.WriteString(wr, fabric.ExportGraphML("fabric", triples))
+
+	case "gexf":
+		wr.Header().Set("Content-Type", "application/gexf+xml")
+		wr.WriteHeader(status)
+		io.WriteString(wr, fabric.ExportGEXF("fabric", triples))
+
+	case "cytoscape":
+		data, err := fabric.ExportCytoscape(triples)
+		if err != nil {
+			writeError(wr, req, http.StatusInternalServerError, err)
+			return
+		}
+
+		wr.Header().Set("Content-Type", "application/json; charset=utf-8")
+		wr.WriteHeader(status)
+		wr.Write(data)
+
+	case "mermaid":
+		wr.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		wr.WriteHeader(status)
+		io.WriteString(wr, fabric.ExportMermaid(triples))
 
 	case "plot":
+		opts, err := readDOTOptions(req.URL.Query(), triples)
+		if err != nil {
+			writeError(wr, req, http.StatusBadRequest, err)
+			return
+		}
+
+		var sb strings.Builder
+		writeDOT(&sb, triples, *opts)
+
+		// the json encoding is a valid javascript string literal that is
+		// safe to embed in the script.
+		dot, _ := json.Marshal(sb.String())
 		plotTemplate.Execute(wr, map[string]interface{}{
-			"graphVizStr": "`" + fabric.ExportDOT("fabric", triples) + "`",
+			"graphVizStr": string(dot),
 		})
 
 	default:
@@ -159,6 +508,33 @@ func writeTriples(wr http.ResponseWriter, req *http.Request, status int, triples
 	}
 }
 
+// writeError writes the error with a status derived from the error type.
+// fallback is used if the error is not one of the known fabric errors.
+func writeError(wr http.ResponseWriter, req *http.Request, fallback int, err error) {
+	status := fallback
+	switch {
+	case errors.Is(err, fabric.ErrConflict):
+		status = http.StatusConflict
+
+	case errors.Is(err, fabric.ErrInvalidTriple):
+		status = http.StatusUnprocessableEntity
+
+	case errors.Is(err, fabric.ErrUnsupportedClause), errors.Is(err, fabric.ErrInvalidClause),
+		errors.Is(err, fabric.ErrInvalidQuery):
+		status = http.StatusBadRequest
+
+	case errors.Is(err, fabric.ErrNotFound), errors.Is(err, fabric.ErrNoPath):
+		status = http.StatusNotFound
+
+	case errors.Is(err, fabric.ErrNotSupported):
+		status = http.StatusNotImplemented
+	}
+
+	writeResponse(wr, req, status, map[string]string{
+		"error": err.Error(),
+	})
+}
+
 func writeResponse(wr http.ResponseWriter, req *http.Request, status int, body interface{}) {
 	wr.Header().Set("Content-Type", "application/json; charset=utf-8")
 	wr.WriteHeader(status)
@@ -186,24 +562,224 @@ func readQuery(vals url.Values) (*fabric.Query, error) {
 		return nil, err
 	}
 
+	// property clauses are given as 'property.<name>=<type> <value>'. an
+	// empty value selects triples having the property.
+	for key := range vals {
+		if !strings.HasPrefix(key, "property.") {
+			continue
+		}
+
+		var cl fabric.Clause
+		if err := readInto(vals, key, &cl); err != nil {
+			return nil, err
+		}
+
+		if q.Properties == nil {
+			q.Properties = map[string]fabric.Clause{}
+		}
+		q.Properties[strings.TrimPrefix(key, "property.")] = cl
+	}
+
+	var err error
+	if q.Limit, err = readInt(vals, "limit"); err != nil {
+		return nil, err
+	}
+
+	if q.Offset, err = readInt(vals, "offset"); err != nil {
+		return nil, err
+	}
+	q.Cursor = vals.Get("cursor")
+
+	if q.OrderBy, err = fabric.ParseOrder(vals.Get("order")); err != nil {
+		return nil, err
+	}
+
 	return &q, nil
 }
 
+func readPathOptions(vals url.Values) (*fabric.PathOptions, error) {
+	var err error
+	var opts fabric.PathOptions
+	opts.Predicates = readList(vals, "predicates")
+
+	if opts.MaxDepth, err = readInt(vals, "max_depth"); err != nil {
+		return nil, err
+	}
+
+	if opts.Undirected, err = readBool(vals, "undirected"); err != nil {
+		return nil, err
+	}
+
+	if opts.Weighted, err = readBool(vals, "weighted"); err != nil {
+		return nil, err
+	}
+
+	return &opts, nil
+}
+
+func readTraverseOptions(vals url.Values) (*fabric.TraverseOptions, error) {
+	var err error
+	var opts fabric.TraverseOptions
+	opts.Predicates = readList(vals, "predicates")
+
+	if opts.Depth, err = readInt(vals, "depth"); err != nil {
+		return nil, err
+	}
+
+	if opts.Direction, err = fabric.ParseDirection(vals.Get("direction")); err != nil {
+		return nil, err
+	}
+
+	if err := readInto(vals, "weight", &opts.Weight); err != nil {
+		return nil, err
+	}
+
+	return &opts, nil
+}
+
+// readDOTOptions reads the styling options of the dot output. dot_cluster
+// names a predicate and nodes are clustered by their target for it.
+func readDOTOptions(vals url.Values, triples []fabric.Triple) (*fabric.DOTOptions, error) {
+	var err error
+	var opts fabric.DOTOptions
+	if opts.Undirected, err = readBool(vals, "dot_undirected"); err != nil {
+		return nil, err
+	}
+
+	if opts.ColorByPredicate, err = readBool(vals, "dot_colors"); err != nil {
+		return nil, err
+	}
+
+	if s := vals.Get("dot_penwidth"); s != "" {
+		if opts.PenWidthScale, err = strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("invalid dot_penwidth: %v", err)
+		}
+	}
+
+	if predicate := vals.Get("dot_cluster"); predicate != "" {
+		clusters := map[string]string{}
+		for _, tri := range triples {
+			if tri.Predicate == predicate {
+				clusters[tri.Source] = tri.Target
+			}
+		}
+		opts.Cluster = func(node string) string { return clusters[node] }
+	}
+
+	return &opts, nil
+}
+
+func writeDOT(w io.Writer, triples []fabric.Triple, opts fabric.DOTOptions) error {
+	dw := fabric.NewDOTWriter(w, "fabric", opts)
+	for _, tri := range triples {
+		if err := dw.Write(tri); err != nil {
+			return err
+		}
+	}
+	return dw.Flush()
+}
+
+func readList(vals url.Values, name string) []string {
+	var list []string
+	for _, item := range strings.Split(vals.Get(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func readInt(vals url.Values, name string) (int, error) {
+	s := vals.Get(name)
+	if s == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return i, nil
+}
+
+func readBool(vals url.Values, name string) (bool, error) {
+	s := vals.Get(name)
+	if s == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return b, nil
+}
+
+// clauseTypes are the known clause types (including the aliases) sorted by
+// length so that the longest type matching a clause is picked first.
+var clauseTypes = sortedByLength([]string{
+	"eq", "neq", "in", "not_in", "not in", "like", "not like", "not_like",
+	"prefix", "suffix", "contains", "regex", "regexp", "gt", "gte", "lt",
+	"lte", "equal", "equals", "==", "=", ">", "greater-than", "<",
+	"lesser-than", "<=", ">=", "~", "~=", "!~", "!=", "<>", "not-equal",
+})
+
+// readInto reads the clause in the form '<type> <value>'. Clause types and
+// values may contain spaces (e.g., 'not like x y' or 'in Bob, Alice').
 func readInto(vals url.Values, name string, cl *fabric.Clause) error {
-	parts := strings.Fields(vals.Get(name))
-	if len(parts) == 0 {
+	raw := strings.TrimSpace(vals.Get(name))
+	if raw == "" {
 		return nil
 	}
 
-	if len(parts) != 2 {
+	// unknown clause types are read up to the first space and left to the
+	// store to reject.
+	typ := strings.Fields(raw)[0]
+	for _, known := range clauseTypes {
+		rest := strings.TrimPrefix(raw, known)
+		if rest != raw && rest != "" && unicode.IsSpace(rune(rest[0])) {
+			typ = known
+			break
+		}
+	}
+
+	value := strings.TrimSpace(raw[len(typ):])
+	if value == "" {
 		return fmt.Errorf("invalid %s clause", name)
 	}
 
-	cl.Type = parts[0]
-	cl.Value = parts[1]
+	cl.Type, cl.Value = typ, value
 	return nil
 }
 
+func sortedByLength(items []string) []string {
+	sort.SliceStable(items, func(i, j int) bool {
+		return len(items[i]) > len(items[j])
+	})
+	return items
+}
+
+// importFormat returns the format of the request body from the format
+// parameter or the content type (defaults to csv).
+func importFormat(req *http.Request) string {
+	if f := strings.ToLower(strings.TrimSpace(req.URL.Query().Get("format"))); f != "" {
+		return f
+	}
+
+	switch ct := req.Header.Get("Content-Type"); {
+	case strings.HasPrefix(ct, "text/tab-separated-values"):
+		return "tsv"
+
+	case strings.HasPrefix(ct, "application/n-triples"):
+		return "nt"
+
+	case strings.HasPrefix(ct, "text/turtle"):
+		return "ttl"
+	}
+
+	return "csv"
+}
+
 func outputFormat(req *http.Request) string {
 	f := strings.TrimSpace(req.URL.Query().Get("format"))
 	if f != "" {
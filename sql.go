@@ -3,24 +3,35 @@ package fabric
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 var (
-	_ Store      = &SQLStore{}
-	_ ReWeighter = &SQLStore{}
-	_ Counter    = &SQLStore{}
+	_ Store        = &SQLStore{}
+	_ ReWeighter   = &SQLStore{}
+	_ Counter      = &SQLStore{}
+	_ Joiner       = &SQLStore{}
+	_ Transactor   = &SQLStore{}
+	_ BulkInserter = &SQLStore{}
+	_ Upserter     = &SQLStore{}
+	_ Returner     = &SQLStore{}
 )
 
 // SQLStore implements Store interface using the Go standard library
-// sql package.
+// sql package. Queries with regex clauses require the database to provide
+// a REGEXP function (e.g., MatchRegexp registered with the sqlite driver).
 type SQLStore struct {
 	DB *sql.DB
+
+	tx *sql.Tx
 }
 
-// Count returns the number of triples that match the given query.
+// Count returns the number of triples that match the given query. Limit,
+// Offset and Cursor of the query are ignored.
 func (ss *SQLStore) Count(ctx context.Context, query Query) (int, error) {
 	sq := `SELECT count(*) FROM triples`
 
@@ -29,15 +40,11 @@ func (ss *SQLStore) Count(ctx context.Context, query Query) (int, error) {
 		return 0, err
 	}
 	if where != "" {
-		sq += fmt.Sprintf("WHERE %s", where)
-	}
-
-	if query.Limit > 0 {
-		sq = fmt.Sprintf("%s LIMIT %d", sq, query.Limit)
+		sq += fmt.Sprintf(" WHERE %s", where)
 	}
 
 	var count int64
-	row := ss.DB.QueryRowContext(ctx, sq, args...)
+	row := ss.conn().QueryRowContext(ctx, sq, args...)
 	if err := row.Scan(&count); err != nil {
 		return 0, err
 	}
@@ -45,47 +52,215 @@ func (ss *SQLStore) Count(ctx context.Context, query Query) (int, error) {
 	return int(count), nil
 }
 
-// Insert persists the given triple into the triples table.
+// Insert persists the given triple into the triples table. Returns ErrConflict
+// if the triple already exists.
 func (ss *SQLStore) Insert(ctx context.Context, tri Triple) error {
-	query := `INSERT INTO triples (source, predicate, target, weight) VALUES (?, ?, ?, ?)`
+	props, err := encodeProperties(tri.Properties)
+	if err != nil {
+		return err
+	}
 
-	_, err := ss.DB.ExecContext(ctx, query, tri.Source, tri.Predicate, tri.Target, tri.Weight)
-	return err
+	return ss.atomic(ctx, len(tri.Properties) > 0, func(conn *SQLStore) error {
+		query := `INSERT INTO triples (source, predicate, target, weight, properties) VALUES (?, ?, ?, ?, ?)`
+
+		_, err := conn.conn().ExecContext(ctx, query, tri.Source, tri.Predicate, tri.Target, tri.Weight, props)
+		if err != nil {
+			if conn.exists(ctx, tri) {
+				// constraint errors are driver specific. so, check for
+				// existence instead of inspecting the error.
+				return ErrConflict
+			}
+			return err
+		}
+
+		return conn.putProperties(ctx, tri, false)
+	})
 }
 
-// Query converts the given query object into SQL SELECT and fetches all the triples.
-func (ss *SQLStore) Query(ctx context.Context, query Query) ([]Triple, error) {
-	sq := `SELECT * FROM triples`
+// Upsert inserts the triple or resolves the conflict with an existing triple
+// using INSERT ... ON CONFLICT as per the given policy.
+func (ss *SQLStore) Upsert(ctx context.Context, tri Triple, policy ConflictPolicy) error {
+	var onConflict string
+	switch policy {
+	case KeepExisting:
+		onConflict = "DO NOTHING"
 
-	where, args, err := getWhereClause(query)
+	case ReplaceWeight:
+		onConflict = "DO UPDATE SET weight = excluded.weight"
+
+	case AddWeight:
+		onConflict = "DO UPDATE SET weight = triples.weight + excluded.weight"
+
+	case MaxWeight:
+		onConflict = "DO UPDATE SET weight = CASE WHEN excluded.weight > triples.weight THEN excluded.weight ELSE triples.weight END"
+
+	default:
+		return fmt.Errorf("invalid conflict policy '%s'", policy)
+	}
+
+	props, err := encodeProperties(tri.Properties)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if where != "" {
-		sq += fmt.Sprintf(" WHERE %s", where)
+
+	// existing properties are kept if the triple has none.
+	if policy != KeepExisting && len(tri.Properties) > 0 {
+		onConflict += ", properties = excluded.properties"
 	}
 
-	if query.Limit > 0 {
-		sq = fmt.Sprintf("%s LIMIT %d", sq, query.Limit)
+	return ss.atomic(ctx, true, func(conn *SQLStore) error {
+		query := `INSERT INTO triples (source, predicate, target, weight, properties) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (source, predicate, target) ` + onConflict
+
+		res, err := conn.conn().ExecContext(ctx, query, tri.Source, tri.Predicate, tri.Target, tri.Weight, props)
+		if err != nil {
+			return err
+		}
+
+		if policy == KeepExisting {
+			if n, err := res.RowsAffected(); err != nil || n == 0 {
+				// existing triple was left unchanged.
+				return err
+			}
+		}
+
+		if len(tri.Properties) == 0 {
+			return nil
+		}
+		return conn.putProperties(ctx, tri, true)
+	})
+}
+
+// InsertMany inserts the triples using multi-row INSERT statements. If a
+// statement fails due to a duplicate, the triples in that statement are
+// inserted one at a time within a transaction to identify the ones that
+// failed. Any other failure is returned as is.
+func (ss *SQLStore) InsertMany(ctx context.Context, triples []Triple) ([]BatchError, error) {
+	var failed []BatchError
+	for start := 0; start < len(triples); start += sqlBulkSize {
+		end := start + sqlBulkSize
+		if end > len(triples) {
+			end = len(triples)
+		}
+		chunk := triples[start:end]
+
+		hasProps := false
+		values := make([]string, len(chunk))
+		args := make([]interface{}, 0, 5*len(chunk))
+		for i, tri := range chunk {
+			props, err := encodeProperties(tri.Properties)
+			if err != nil {
+				return nil, err
+			}
+
+			hasProps = hasProps || props != nil
+			values[i] = "(?, ?, ?, ?, ?)"
+			args = append(args, tri.Source, tri.Predicate, tri.Target, tri.Weight, props)
+		}
+
+		err := ss.atomic(ctx, hasProps, func(conn *SQLStore) error {
+			sq := "INSERT INTO triples (source, predicate, target, weight, properties) VALUES " + strings.Join(values, ", ")
+			if _, err := conn.conn().ExecContext(ctx, sq, args...); err != nil {
+				return err
+			}
+
+			for _, tri := range chunk {
+				if err := conn.putProperties(ctx, tri, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			continue
+		} else if !ss.hasConflicts(ctx, chunk) {
+			return nil, err
+		}
+
+		var chunkFailed []BatchError
+		err = ss.atomic(ctx, true, func(conn *SQLStore) error {
+			for i, tri := range chunk {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				if err := conn.Insert(ctx, tri); errors.Is(err, ErrConflict) {
+					chunkFailed = append(chunkFailed, BatchError{Index: start + i, Err: err})
+				} else if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		failed = append(failed, chunkFailed...)
 	}
 
-	rows, err := ss.DB.QueryContext(ctx, sq, args...)
+	return failed, nil
+}
+
+// hasConflicts returns true if any of the triples already exists or if the
+// triples contain duplicates.
+func (ss *SQLStore) hasConflicts(ctx context.Context, triples []Triple) bool {
+	seen := map[[3]string]bool{}
+	for _, tri := range triples {
+		key := [3]string{tri.Source, tri.Predicate, tri.Target}
+		if seen[key] || ss.exists(ctx, tri) {
+			return true
+		}
+		seen[key] = true
+	}
+	return false
+}
+
+// Query converts the given query object into SQL SELECT and fetches all the triples.
+func (ss *SQLStore) Query(ctx context.Context, query Query) ([]Triple, error) {
+	var triples []Triple
+	err := ss.Iterate(ctx, query, func(tri Triple) error {
+		triples = append(triples, tri)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return triples, nil
+}
+
+// Iterate converts the given query object into SQL SELECT and calls fn for
+// every row as it is read from the database. A connection is held for the
+// duration of the iteration.
+func (ss *SQLStore) Iterate(ctx context.Context, query Query, fn func(tri Triple) error) error {
+	sq, args, err := getSelectQuery(query)
+	if err != nil {
+		return err
+	}
+
+	rows, err := ss.conn().QueryContext(ctx, sq, args...)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
-	var triples []Triple
 	for rows.Next() {
 		var tri Triple
-		if err := rows.Scan(&tri.Source, &tri.Predicate, &tri.Target, &tri.Weight); err != nil {
-			return nil, err
+		var props sql.NullString
+		if err := rows.Scan(&tri.Source, &tri.Predicate, &tri.Target, &tri.Weight, &props); err != nil {
+			return err
 		}
 
-		triples = append(triples, tri)
+		if tri.Properties, err = decodeProperties(props.String); err != nil {
+			return err
+		}
+
+		if err := fn(tri); err != nil {
+			return err
+		}
 	}
 
-	return triples, nil
+	return rows.Err()
 }
 
 // Delete removes all the triples from the database that match the query.
@@ -97,12 +272,12 @@ func (ss *SQLStore) Delete(ctx context.Context, query Query) (int, error) {
 		return 0, err
 	}
 	if where == "" {
-		return 0, errors.New("no query clause specified")
+		return 0, errNoClause
 	}
 
 	q := fmt.Sprintf(sq, where)
 
-	res, err := ss.DB.ExecContext(ctx, q, args...)
+	res, err := ss.conn().ExecContext(ctx, q, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
@@ -138,7 +313,7 @@ func (ss *SQLStore) ReWeight(ctx context.Context, query Query, delta float64, re
 		args = append(args, tmp...)
 	}
 
-	res, err := ss.DB.ExecContext(ctx, sq, args...)
+	res, err := ss.conn().ExecContext(ctx, sq, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
@@ -155,57 +330,635 @@ func (ss *SQLStore) ReWeight(ctx context.Context, query Query, delta float64, re
 	return int(count), nil
 }
 
+// DeleteReturning removes all the triples that match the query and returns
+// them. The triples are read and deleted within a single transaction.
+func (ss *SQLStore) DeleteReturning(ctx context.Context, query Query) ([]Triple, error) {
+	var triples []Triple
+	err := ss.atomic(ctx, true, func(conn *SQLStore) error {
+		var err error
+		if triples, err = conn.Query(ctx, query.unpaged()); err != nil {
+			return err
+		}
+
+		_, err = conn.Delete(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return triples, nil
+}
+
+// ReWeightReturning updates the weight of all the triples matching the query
+// and returns the updated triples. The triples are read and updated within a
+// single transaction.
+func (ss *SQLStore) ReWeightReturning(ctx context.Context, query Query, delta float64, replace bool) ([]Triple, error) {
+	var triples []Triple
+	err := ss.atomic(ctx, true, func(conn *SQLStore) error {
+		var err error
+		if triples, err = conn.Query(ctx, query.unpaged()); err != nil {
+			return err
+		}
+
+		_, err = conn.ReWeight(ctx, query, delta, replace)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tri := range triples {
+		triples[i] = reweighted(tri, delta, replace)
+	}
+	return triples, nil
+}
+
+// Join evaluates the patterns using a single SELECT query with a self-join of
+// the triples table for every pattern. Rows are streamed into fn and the query
+// is abandoned as soon as fn returns false.
+func (ss *SQLStore) Join(ctx context.Context, patterns []Pattern, fn func(Binding) (bool, error)) error {
+	var tables, where, vars, cols []string
+	var args []interface{}
+	bound := map[string]string{}
+
+	for i, pat := range patterns {
+		alias := fmt.Sprintf("t%d", i)
+		tables = append(tables, "triples "+alias)
+
+		terms := [][2]string{
+			{"source", pat.Source},
+			{"predicate", pat.Predicate},
+			{"target", pat.Target},
+		}
+		for _, t := range terms {
+			col := alias + "." + t[0]
+
+			name, isVar := variableName(t[1])
+			if !isVar {
+				where = append(where, col+" = ?")
+				args = append(args, t[1])
+				continue
+			}
+
+			if prev, found := bound[name]; found {
+				where = append(where, col+" = "+prev)
+				continue
+			}
+
+			bound[name] = col
+			vars = append(vars, name)
+			cols = append(cols, col)
+		}
+
+		if !pat.Weight.IsAny() {
+			cond, condArgs, err := toSQL(alias+".weight", pat.Weight)
+			if err != nil {
+				return err
+			}
+
+			where = append(where, cond)
+			args = append(args, condArgs...)
+		}
+	}
+
+	if len(cols) == 0 {
+		// patterns have no variables, select a constant per matching row.
+		cols = []string{"1"}
+	}
+
+	sq := fmt.Sprintf("SELECT %s FROM %s", strings.Join(cols, ", "), strings.Join(tables, ", "))
+	if len(where) > 0 {
+		sq += fmt.Sprintf(" WHERE %s", strings.Join(where, " AND "))
+	}
+
+	rows, err := ss.conn().QueryContext(ctx, sq, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		vals := make([]string, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+
+		b := Binding{}
+		for i, name := range vars {
+			b[name] = vals[i]
+		}
+
+		more, err := fn(b)
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Begin starts a new transaction using the underlying database.
+func (ss *SQLStore) Begin(ctx context.Context) (Tx, error) {
+	if ss.tx != nil {
+		return nil, errors.New("nested transactions are not supported")
+	}
+
+	tx, err := ss.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlTx{SQLStore: &SQLStore{DB: ss.DB, tx: tx}}, nil
+}
+
 // Setup runs appropriate queries to setup all the required tables.
 func (ss *SQLStore) Setup(ctx context.Context) error {
-	_, err := ss.DB.ExecContext(ctx, sqlMigration)
+	if _, err := ss.conn().ExecContext(ctx, sqlMigration); err != nil {
+		return err
+	}
+
+	// tables created before properties were supported lack the column.
+	rows, err := ss.conn().QueryContext(ctx, `SELECT properties FROM triples LIMIT 0`)
+	if err == nil {
+		return rows.Close()
+	}
+
+	_, err = ss.conn().ExecContext(ctx, `ALTER TABLE triples ADD COLUMN properties text`)
+	return err
+}
+
+// atomic runs fn within a transaction if required and the store is not
+// already in one.
+func (ss *SQLStore) atomic(ctx context.Context, required bool, fn func(conn *SQLStore) error) error {
+	if !required || ss.tx != nil {
+		return fn(ss)
+	}
+
+	tx, err := ss.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&SQLStore{DB: ss.DB, tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// putProperties writes the properties of the triple to the properties table
+// which is used for filtering on properties. If replace is true, existing
+// properties of the triple are removed first.
+func (ss *SQLStore) putProperties(ctx context.Context, tri Triple, replace bool) error {
+	if replace {
+		sq := `DELETE FROM triple_properties WHERE source = ? AND predicate = ? AND target = ?`
+		if _, err := ss.conn().ExecContext(ctx, sq, tri.Source, tri.Predicate, tri.Target); err != nil {
+			return err
+		}
+	}
+
+	if len(tri.Properties) == 0 {
+		return nil
+	}
+
+	var values []string
+	var args []interface{}
+	for name, val := range tri.Properties {
+		var num interface{}
+		if f, isNum := val.(float64); isNum {
+			num = f
+		}
+
+		values = append(values, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, tri.Source, tri.Predicate, tri.Target, name, propertyText(val), num)
+	}
+
+	sq := "INSERT INTO triple_properties (source, predicate, target, name, value_text, value_num) VALUES " + strings.Join(values, ", ")
+	_, err := ss.conn().ExecContext(ctx, sq, args...)
 	return err
 }
 
+func (ss *SQLStore) exists(ctx context.Context, tri Triple) bool {
+	query := `SELECT count(*) FROM triples WHERE source = ? AND predicate = ? AND target = ?`
+
+	var count int64
+	row := ss.conn().QueryRowContext(ctx, query, tri.Source, tri.Predicate, tri.Target)
+	return row.Scan(&count) == nil && count > 0
+}
+
+func (ss *SQLStore) conn() sqlConn {
+	if ss.tx != nil {
+		return ss.tx
+	}
+	return ss.DB
+}
+
+// sqlConn is implemented by both sql.DB and sql.Tx.
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlTx is a SQLStore bound to a database transaction.
+type sqlTx struct {
+	*SQLStore
+}
+
+func (tx *sqlTx) Commit() error {
+	if err := tx.tx.Commit(); err != sql.ErrTxDone {
+		return err
+	}
+	return ErrTxDone
+}
+
+func (tx *sqlTx) Rollback() error {
+	if err := tx.tx.Rollback(); err != sql.ErrTxDone {
+		return err
+	}
+	return ErrTxDone
+}
+
+func getSelectQuery(query Query) (string, []interface{}, error) {
+	sq := `SELECT source, predicate, target, weight, properties FROM triples`
+
+	where, args, err := getWhereClause(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys, err := query.sortKeys()
+	if err != nil {
+		return "", nil, err
+	}
+
+	after, afterArgs, err := getCursorClause(query, keys)
+	if err != nil {
+		return "", nil, err
+	}
+	if after != "" {
+		if where != "" {
+			where += " AND "
+		}
+		where += after
+		args = append(args, afterArgs...)
+	}
+
+	if where != "" {
+		sq += fmt.Sprintf(" WHERE %s", where)
+	}
+	sq += " ORDER BY " + getOrderByClause(keys)
+
+	offset := query.skip()
+	if query.Limit > 0 {
+		sq = fmt.Sprintf("%s LIMIT %d", sq, query.Limit)
+	} else if offset > 0 {
+		// sqlite requires a limit clause for using offset.
+		sq += " LIMIT -1"
+	}
+
+	if offset > 0 {
+		sq = fmt.Sprintf("%s OFFSET %d", sq, offset)
+	}
+
+	return sq, args, nil
+}
+
+// getOrderByClause returns the ORDER BY terms for the keys. The keys must be
+// validated against the known columns using Query.sortKeys.
+func getOrderByClause(keys []Order) string {
+	terms := make([]string, len(keys))
+	for i, key := range keys {
+		dir := "ASC"
+		if key.Desc {
+			dir = "DESC"
+		}
+		terms[i] = key.Field + " " + dir
+	}
+	return strings.Join(terms, ", ")
+}
+
+// getCursorClause returns the keyset condition selecting the rows after the
+// cursor of the query, i.e., (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with
+// the comparison flipped for descending keys.
+func getCursorClause(query Query, keys []Order) (string, []interface{}, error) {
+	last, err := query.after(keys)
+	if err != nil || last == nil {
+		return "", nil, err
+	}
+
+	values := map[string]interface{}{
+		"source":    last.Source,
+		"predicate": last.Predicate,
+		"target":    last.Target,
+		"weight":    last.Weight,
+	}
+
+	var terms []string
+	var args []interface{}
+	for i, key := range keys {
+		var conds []string
+		for _, prev := range keys[:i] {
+			conds = append(conds, prev.Field+" = ?")
+			args = append(args, values[prev.Field])
+		}
+
+		op := ">"
+		if key.Desc {
+			op = "<"
+		}
+		conds = append(conds, fmt.Sprintf("%s %s ?", key.Field, op))
+		args = append(args, values[key.Field])
+
+		terms = append(terms, "("+strings.Join(conds, " AND ")+")")
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", args, nil
+}
+
+// getWhereClause returns the condition for the query. Clauses of the query
+// are ANDed together along with the ORed conditions of the Or queries and the
+// negated condition of the Not query.
 func getWhereClause(query Query) (string, []interface{}, error) {
 	var where []string
 	var args []interface{}
 	for col, clause := range query.Map() {
-		sqlOp, value, err := toSQL(clause)
+		clause.normalize()
+
+		var cond string
+		var condArgs []interface{}
+		var err error
+		if col == "target" && isNumericClause(clause.Type) {
+			cond, condArgs, err = literalToSQL(col, clause)
+		} else {
+			cond, condArgs, err = toSQL(col, clause)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	for name, clause := range query.Properties {
+		cond, condArgs, err := propertyToSQL(name, clause)
+		if err != nil {
+			return "", nil, err
+		}
+
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	if len(query.Or) > 0 {
+		var terms []string
+		for _, q := range query.Or {
+			cond, condArgs, err := getSubWhereClause(q)
+			if err != nil {
+				return "", nil, err
+			}
+
+			terms = append(terms, cond)
+			args = append(args, condArgs...)
+		}
+		where = append(where, "("+strings.Join(terms, " OR ")+")")
+	}
+
+	if query.Not != nil {
+		cond, condArgs, err := getSubWhereClause(*query.Not)
 		if err != nil {
 			return "", nil, err
 		}
 
-		where = append(where, fmt.Sprintf("%s %s ?", col, sqlOp))
-		args = append(args, value)
+		where = append(where, "NOT "+cond)
+		args = append(args, condArgs...)
 	}
+
 	return strings.TrimSpace(strings.Join(where, " AND ")), args, nil
 }
 
-func toSQL(clause Clause) (string, string, error) {
+// getSubWhereClause returns the parenthesized condition for a query nested
+// in Or or Not. A query without any clauses matches all the triples.
+func getSubWhereClause(query Query) (string, []interface{}, error) {
+	cond, args, err := getWhereClause(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cond == "" {
+		cond = "1 = 1"
+	}
+	return "(" + cond + ")", args, nil
+}
+
+// toSQL returns the condition on the column for the clause along with the
+// arguments for the placeholders.
+func toSQL(col string, clause Clause) (string, []interface{}, error) {
+	values := []string{clause.Value}
+	if clause.Type == "in" || clause.Type == "not_in" {
+		values = clause.list()
+	}
+
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+
+	if col == "weight" || strings.HasSuffix(col, ".weight") {
+		for i, v := range values {
+			w, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("%w: weight must be a number, got '%s'", ErrInvalidClause, v)
+			}
+			args[i] = w
+		}
+
+		switch clause.Type {
+		case "eq", "neq", "gt", "gte", "lt", "lte", "in", "not_in":
+
+		default:
+			return "", nil, fmt.Errorf("%w: '%s' on weight", ErrUnsupportedClause, clause.Type)
+		}
+	}
+
+	var sqlOp string
 	switch clause.Type {
 	case "eq":
-		return "=", clause.Value, nil
+		sqlOp = "="
+
+	case "neq":
+		sqlOp = "<>"
+
+	case "like", "not like":
+		// like patterns only support '*' as the wildcard, so the wildcards
+		// of SQL LIKE are escaped.
+		op := strings.ToUpper(clause.Type)
+		return fmt.Sprintf(`%s %s ? ESCAPE '\'`, col, op), []interface{}{sqlLikePattern(clause.Value)}, nil
+
+	case "prefix":
+		return fmt.Sprintf("substr(%s, 1, length(?)) = ?", col), []interface{}{clause.Value, clause.Value}, nil
+
+	case "suffix":
+		cond := fmt.Sprintf("(length(%[1]s) >= length(?) AND substr(%[1]s, length(%[1]s) - length(?) + 1) = ?)", col)
+		return cond, []interface{}{clause.Value, clause.Value, clause.Value}, nil
 
-	case "like":
-		return " LIKE ", strings.Replace(clause.Value, "*", "%", -1), nil
+	case "contains":
+		return fmt.Sprintf("instr(%s, ?) > 0", col), args, nil
+
+	case "regex":
+		// validate the pattern so that errors are the same as other stores.
+		if _, err := MatchRegexp(clause.Value, ""); err != nil {
+			return "", nil, err
+		}
+		sqlOp = "REGEXP"
 
 	case "gt":
-		return ">", clause.Value, nil
+		sqlOp = ">"
 
 	case "lt":
-		return "<", clause.Value, nil
+		sqlOp = "<"
 
 	case "lte":
-		return "<=", clause.Value, nil
+		sqlOp = "<="
 
 	case "gte":
-		return ">=", clause.Value, nil
+		sqlOp = ">="
+
+	case "in", "not_in":
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+		op := "IN"
+		if clause.Type == "not_in" {
+			op = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", col, op, placeholders), args, nil
+
+	default:
+		return "", nil, fmt.Errorf("%w: '%s'", ErrUnsupportedClause, clause.Type)
 	}
 
-	return "", "", fmt.Errorf("clause type '%s' not supported", clause.Type)
+	return fmt.Sprintf("%s %s ?", col, sqlOp), args, nil
 }
 
+// literalToSQL returns the condition for a range clause on the literal values
+// of the column (see Object). Entities and literals of other datatypes never
+// match.
+func literalToSQL(col string, clause Clause) (string, []interface{}, error) {
+	bound, err := parseLiteralBound(clause.Value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ops := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	value := fmt.Sprintf(`substr(%[1]s, 2, instr(%[1]s, '"^^') - 2)`, col)
+
+	if bound.date {
+		cond := fmt.Sprintf(`(%s GLOB '"*"^^date' AND %s %s ?)`, col, value, ops[clause.Type])
+		return cond, []interface{}{bound.lex}, nil
+	}
+
+	cond := fmt.Sprintf(`((%[1]s GLOB '"*"^^int' OR %[1]s GLOB '"*"^^float') AND CAST(%[2]s AS REAL) %[3]s ?)`,
+		col, value, ops[clause.Type])
+	return cond, []interface{}{bound.num}, nil
+}
+
+// propertyToSQL returns the condition selecting the triples having the named
+// property with a value matching the clause.
+func propertyToSQL(name string, clause Clause) (string, []interface{}, error) {
+	sq := `EXISTS (SELECT 1 FROM triple_properties p WHERE p.source = triples.source
+		AND p.predicate = triples.predicate AND p.target = triples.target AND p.name = ?`
+	args := []interface{}{name}
+
+	if !clause.IsAny() {
+		col := "p.value_text"
+		if isNumericClause(clause.Type) {
+			w, err := parseWeight(clause.Value)
+			if err != nil {
+				return "", nil, err
+			}
+			clause.Value = strconv.FormatFloat(w, 'f', -1, 64)
+			col = "p.value_num"
+		}
+
+		cond, condArgs, err := toSQL(col, clause)
+		if err != nil {
+			return "", nil, err
+		}
+
+		sq += " AND " + cond
+		args = append(args, condArgs...)
+	}
+
+	return sq + ")", args, nil
+}
+
+func encodeProperties(props map[string]interface{}) (interface{}, error) {
+	if len(props) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(props)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func decodeProperties(data string) (map[string]interface{}, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	var props map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &props); err != nil {
+		return nil, err
+	}
+
+	if len(props) == 0 {
+		return nil, nil
+	}
+	return props, nil
+}
+
+func sqlLikePattern(pattern string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%")
+	return r.Replace(pattern)
+}
+
+// sqlBulkSize is the number of rows inserted per statement by InsertMany. It
+// keeps the number of bind variables (5 per row) under the default sqlite
+// limit of 999.
+const sqlBulkSize = 999 / 5
+
 const sqlMigration = `
 create table if not exists triples (
 	source text not null,
 	predicate text not null,
 	target text not null,
-	weight decimal not null default 0
+	weight decimal not null default 0,
+	properties text
 );
 create unique index if not exists triple_idx on triples (source, predicate, target);
+create table if not exists triple_properties (
+	source text not null,
+	predicate text not null,
+	target text not null,
+	name text not null,
+	value_text text not null,
+	value_num real
+);
+create unique index if not exists triple_properties_idx on triple_properties (source, predicate, target, name);
+create index if not exists triple_properties_name_idx on triple_properties (name, value_text);
+create trigger if not exists triple_properties_delete after delete on triples
+begin
+	delete from triple_properties
+	where source = old.source and predicate = old.predicate and target = old.target;
+end;
 `
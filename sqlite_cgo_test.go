@@ -0,0 +1,93 @@
+//go:build cgo
+// +build cgo
+
+package fabric_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/spy16/fabric"
+	"github.com/spy16/fabric/storetest"
+)
+
+func init() {
+	sql.Register("sqlite3_regexp", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", fabric.MatchRegexp, true)
+		},
+	})
+}
+
+func newSQLStore(t *testing.T) *fabric.SQLStore {
+	db, err := sql.Open("sqlite3_regexp", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	store := &fabric.SQLStore{DB: db}
+	if err := store.Setup(context.Background()); err != nil {
+		t.Fatalf("failed to setup db: %v", err)
+	}
+
+	return store
+}
+
+// withSQLStore adds the SQLStore to the stores used by a test.
+func withSQLStore(t *testing.T, stores map[string]func() fabric.Store) map[string]func() fabric.Store {
+	stores["SQLStore"] = func() fabric.Store { return newSQLStore(t) }
+	return stores
+}
+
+func TestSQLStore_Conformance(t *testing.T) {
+	t.Parallel()
+
+	storetest.Run(t, func(t *testing.T) fabric.Store {
+		return newSQLStore(t)
+	})
+}
+
+func TestSQLStore_InsertMany(t *testing.T) {
+	store := newSQLStore(t)
+	store.DB.Close()
+
+	triples := []fabric.Triple{
+		{Source: "Bob", Predicate: "knows", Target: "John"},
+	}
+
+	if _, err := store.InsertMany(context.Background(), triples); err == nil {
+		t.Errorf("expecting error, got nil")
+	}
+
+	if _, err := fabric.New(storeOnly{store}).InsertMany(context.Background(), triples); err == nil {
+		t.Errorf("expecting error from fallback, got nil")
+	}
+}
+
+func TestSQLStore_Join(t *testing.T) {
+	store := newSQLStore(t)
+	fab := fabric.New(store)
+	for i := 0; i < 20; i++ {
+		insert(t, fab, fabric.Triple{Source: "Bob", Predicate: "knows", Target: fmt.Sprintf("p%d", i)})
+	}
+
+	calls := 0
+	err := store.Join(context.Background(), []fabric.Pattern{
+		{Source: "Bob", Predicate: "knows", Target: "?a"},
+		{Source: "Bob", Predicate: "knows", Target: "?b"},
+	}, func(b fabric.Binding) (bool, error) {
+		calls++
+		return calls < 3, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected join to stop after 3 bindings, got %d", calls)
+	}
+}
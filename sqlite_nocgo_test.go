@@ -0,0 +1,15 @@
+//go:build !cgo
+// +build !cgo
+
+package fabric_test
+
+import (
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+// withSQLStore returns the stores as is since the sqlite driver requires cgo.
+func withSQLStore(t *testing.T, stores map[string]func() fabric.Store) map[string]func() fabric.Store {
+	return stores
+}
@@ -13,7 +13,8 @@ type Store interface {
 	Query(ctx context.Context, q Query) ([]Triple, error)
 
 	// Delete should delete triples from store that match the given clauses.
-	// Clauses will follow same format as used in Query() method.
+	// Clauses will follow same format as used in Query() method. Queries
+	// without any clauses should be rejected.
 	Delete(ctx context.Context, q Query) (int, error)
 }
 
@@ -36,3 +37,82 @@ type Counter interface {
 	// given query.
 	Count(ctx context.Context, query Query) (int, error)
 }
+
+// Upserter can be implemented by Store implementations to support inserting
+// triples that may already exist. In case, this interface is not implemented,
+// upserts will not be supported.
+type Upserter interface {
+	// Upsert should insert the triple if it does not exist. Otherwise, the
+	// weight of the existing triple should be updated as per the policy.
+	Upsert(ctx context.Context, tri Triple, policy ConflictPolicy) error
+}
+
+// BulkInserter can be implemented by Store implementations to support
+// efficient insertion of many triples at once. In case, this interface is not
+// implemented, Insert will be called for every triple.
+type BulkInserter interface {
+	// InsertMany should insert all the given triples. Triples that could not
+	// be inserted (e.g., duplicates) should be reported using their index in
+	// the given slice while the rest are still inserted. A non-nil error
+	// should be returned only if the operation failed as a whole.
+	InsertMany(ctx context.Context, triples []Triple) ([]BatchError, error)
+}
+
+// Iterator can be implemented by Store implementations to stream the results
+// of a query instead of returning them all at once. In case, this interface
+// is not implemented, Query will be used and the results iterated over.
+type Iterator interface {
+	// Iterate should call fn for every triple matching the query in the same
+	// order as Query would return them. If fn returns an error, iteration
+	// should stop and the error should be returned.
+	Iterate(ctx context.Context, query Query, fn func(tri Triple) error) error
+}
+
+// Joiner can be implemented by Store implementations to evaluate multiple
+// patterns natively (e.g., using SQL self-joins). In case, this interface is
+// not implemented, patterns will be joined using nested-loops over Query.
+type Joiner interface {
+	// Join should invoke fn with the bindings of all the variables in the
+	// patterns such that every pattern matches a triple in the store. Pattern
+	// terms starting with '?' are variables and the same variable must be
+	// bound to the same value across all the patterns. The join should stop
+	// as soon as fn returns false or an error.
+	Join(ctx context.Context, patterns []Pattern, fn func(Binding) (bool, error)) error
+}
+
+// Returner can be implemented by Store implementations to return the triples
+// changed by a delete or re-weight as part of the change itself. Change events
+// of Watch are derived from the returned triples. In case, this interface is
+// not implemented, the affected triples are queried before the change is made
+// and the events are best-effort under concurrent writes.
+type Returner interface {
+	// DeleteReturning should work like Delete and return the triples that
+	// were deleted in any order.
+	DeleteReturning(ctx context.Context, query Query) ([]Triple, error)
+
+	// ReWeightReturning should work like ReWeighter.ReWeight and return the
+	// updated triples (with the new weights) in any order.
+	ReWeightReturning(ctx context.Context, query Query, delta float64, replace bool) ([]Triple, error)
+}
+
+// Transactor can be implemented by Store implementations to support atomic
+// batch writes. In case, this interface is not implemented, batch operations
+// will not be supported.
+type Transactor interface {
+	// Begin should start a new transaction. All the changes made through the
+	// returned Tx must be applied atomically on Commit or discarded on
+	// Rollback.
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// Tx represents a store transaction. Tx implementations may also implement
+// optional interfaces such as Counter and ReWeighter.
+type Tx interface {
+	Store
+
+	// Commit should apply all the changes made in the transaction.
+	Commit() error
+
+	// Rollback should discard all the changes made in the transaction.
+	Rollback() error
+}
@@ -0,0 +1,762 @@
+// Package storetest provides a conformance test suite for fabric.Store
+// implementations. The suite verifies that a store produces the same results
+// as the built-in stores for every clause type, limits, ordering, deletes
+// and re-weights.
+//
+//	func TestMyStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) fabric.Store {
+//			return newMyStore(t)
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+// Run runs the conformance suite against the stores returned by newStore.
+// newStore is called once for every test and must return an empty store.
+// SQL stores must have the REGEXP function registered (see fabric.MatchRegexp).
+// Stores are used through fabric.New as applications would. Tests for
+// optional capabilities (e.g., fabric.ReWeighter) are skipped if the store
+// does not implement them.
+func Run(t *testing.T, newStore func(t *testing.T) fabric.Store) {
+	newFabric := func(t *testing.T) *fabric.Fabric {
+		fab := fabric.New(newStore(t))
+		insertFixture(t, fab)
+		return fab
+	}
+
+	t.Run("Insert", func(t *testing.T) { testInsert(t, newFabric(t)) })
+	t.Run("Clauses", func(t *testing.T) { testClauses(t, newFabric(t)) })
+	t.Run("Errors", func(t *testing.T) { testErrors(t, newFabric(t)) })
+	t.Run("Limit", func(t *testing.T) { testLimit(t, newFabric(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newFabric(t)) })
+	t.Run("ReWeight", func(t *testing.T) { testReWeight(t, newFabric(t)) })
+	t.Run("Returner", func(t *testing.T) {
+		store := newStore(t)
+		insertFixture(t, fabric.New(store))
+		testReturner(t, store)
+	})
+	t.Run("Properties", func(t *testing.T) { testProperties(t, newFabric(t)) })
+	t.Run("Literals", func(t *testing.T) { testLiterals(t, newFabric(t)) })
+}
+
+// fixture is ordered by source, predicate and target which is the default
+// order of query results.
+var fixture = []fabric.Triple{
+	{Source: "Alice", Predicate: "knows", Target: "Bob", Weight: 1},
+	{Source: "Bob", Predicate: "knows", Target: "Charlie", Weight: 2},
+	{Source: "Bob", Predicate: "likes", Target: "alice", Weight: 3},
+	{Source: "Dave11", Predicate: "owns", Target: "1000", Weight: 4},
+	{Source: "Dave_1", Predicate: "owns", Target: "100%", Weight: -1},
+	{Source: "charlie", Predicate: "knows", Target: "Bob", Weight: 0.5},
+}
+
+func insertFixture(t *testing.T, fab *fabric.Fabric) {
+	for _, tri := range fixture {
+		if err := fab.Insert(context.Background(), tri); err != nil {
+			t.Fatalf("failed to insert fixture '%s': %v", tri, err)
+		}
+	}
+}
+
+func testInsert(t *testing.T, fab *fabric.Fabric) {
+	assertQuery(t, fab, fabric.Query{}, fixture)
+
+	err := fab.Insert(context.Background(), fixture[0])
+	if !errors.Is(err, fabric.ErrConflict) {
+		t.Errorf("expecting ErrConflict on duplicate insert, got %v", err)
+	}
+}
+
+func testClauses(t *testing.T, fab *fabric.Fabric) {
+	cases := []struct {
+		title    string
+		query    fabric.Query
+		expected []int
+	}{
+		{
+			title:    "Any",
+			query:    fabric.Query{},
+			expected: []int{0, 1, 2, 3, 4, 5},
+		},
+		{
+			title:    "Eq",
+			query:    fabric.Query{Source: clause("eq", "Bob")},
+			expected: []int{1, 2},
+		},
+		{
+			title:    "EqIsCaseSensitive",
+			query:    fabric.Query{Source: clause("eq", "bob")},
+			expected: nil,
+		},
+		{
+			title:    "EqualAlias",
+			query:    fabric.Query{Source: clause("equal", "Bob")},
+			expected: []int{1, 2},
+		},
+		{
+			title:    "DoubleEqualsAlias",
+			query:    fabric.Query{Target: clause("==", "Bob")},
+			expected: []int{0, 5},
+		},
+		{
+			title:    "EqualsAlias",
+			query:    fabric.Query{Predicate: clause("=", "owns")},
+			expected: []int{3, 4},
+		},
+		{
+			title:    "Neq",
+			query:    fabric.Query{Predicate: clause("neq", "knows")},
+			expected: []int{2, 3, 4},
+		},
+		{
+			title:    "In",
+			query:    fabric.Query{Target: clause("in", "Bob, alice")},
+			expected: []int{0, 2, 5},
+		},
+		{
+			title:    "NotIn",
+			query:    fabric.Query{Predicate: clause("not_in", "knows,likes")},
+			expected: []int{3, 4},
+		},
+		{
+			title:    "LikeIsAnchoredAndCaseInsensitive",
+			query:    fabric.Query{Source: clause("like", "bob")},
+			expected: []int{1, 2},
+		},
+		{
+			title:    "LikePrefix",
+			query:    fabric.Query{Target: clause("like", "b*")},
+			expected: []int{0, 5},
+		},
+		{
+			title:    "LikeContains",
+			query:    fabric.Query{Source: clause("like", "*li*")},
+			expected: []int{0, 5},
+		},
+		{
+			title:    "LikeTreatsPercentLiterally",
+			query:    fabric.Query{Target: clause("like", "100%")},
+			expected: []int{4},
+		},
+		{
+			title:    "LikeTreatsUnderscoreLiterally",
+			query:    fabric.Query{Source: clause("like", "Dave_1")},
+			expected: []int{4},
+		},
+		{
+			title:    "LikeAlias",
+			query:    fabric.Query{Source: clause("~", "dave*")},
+			expected: []int{3, 4},
+		},
+		{
+			title:    "NotLike",
+			query:    fabric.Query{Source: clause("not like", "*e*")},
+			expected: []int{1, 2},
+		},
+		{
+			title:    "PrefixIsCaseSensitive",
+			query:    fabric.Query{Source: clause("prefix", "Bo")},
+			expected: []int{1, 2},
+		},
+		{
+			title:    "PrefixTreatsUnderscoreLiterally",
+			query:    fabric.Query{Source: clause("prefix", "Dave_")},
+			expected: []int{4},
+		},
+		{
+			title:    "Suffix",
+			query:    fabric.Query{Target: clause("suffix", "ob")},
+			expected: []int{0, 5},
+		},
+		{
+			title:    "SuffixTreatsPercentLiterally",
+			query:    fabric.Query{Target: clause("suffix", "0%")},
+			expected: []int{4},
+		},
+		{
+			title:    "SuffixLongerThanValue",
+			query:    fabric.Query{Target: clause("suffix", "xBob")},
+			expected: nil,
+		},
+		{
+			title:    "Contains",
+			query:    fabric.Query{Source: clause("contains", "li")},
+			expected: []int{0, 5},
+		},
+		{
+			title:    "ContainsIsCaseSensitive",
+			query:    fabric.Query{Target: clause("contains", "C")},
+			expected: []int{1},
+		},
+		{
+			title:    "RegexIsUnanchored",
+			query:    fabric.Query{Target: clause("regex", "0{3}")},
+			expected: []int{3},
+		},
+		{
+			title:    "RegexAnchored",
+			query:    fabric.Query{Source: clause("regex", "^[A-Z][a-z]+$")},
+			expected: []int{0, 1, 2},
+		},
+		{
+			title:    "WeightEq",
+			query:    fabric.Query{Weight: clause("eq", "2")},
+			expected: []int{1},
+		},
+		{
+			title:    "WeightNeq",
+			query:    fabric.Query{Weight: clause("neq", "1")},
+			expected: []int{1, 2, 3, 4, 5},
+		},
+		{
+			title:    "WeightGt",
+			query:    fabric.Query{Weight: clause("gt", "1")},
+			expected: []int{1, 2, 3},
+		},
+		{
+			title:    "WeightGte",
+			query:    fabric.Query{Weight: clause(">=", "1")},
+			expected: []int{0, 1, 2, 3},
+		},
+		{
+			title:    "WeightLt",
+			query:    fabric.Query{Weight: clause("lt", "1")},
+			expected: []int{4, 5},
+		},
+		{
+			title:    "WeightLte",
+			query:    fabric.Query{Weight: clause("<=", "0.5")},
+			expected: []int{4, 5},
+		},
+		{
+			title:    "WeightIn",
+			query:    fabric.Query{Weight: clause("in", "1,3")},
+			expected: []int{0, 2},
+		},
+		{
+			title:    "WeightNotIn",
+			query:    fabric.Query{Weight: clause("not_in", "1,3")},
+			expected: []int{1, 3, 4, 5},
+		},
+		{
+			title: "MultipleClauses",
+			query: fabric.Query{
+				Source: clause("eq", "Bob"),
+				Weight: clause("gt", "2"),
+			},
+			expected: []int{2},
+		},
+		{
+			title: "Or",
+			query: fabric.Query{
+				Or: []fabric.Query{
+					{Predicate: clause("eq", "likes")},
+					{Weight: clause("lt", "0")},
+				},
+			},
+			expected: []int{2, 4},
+		},
+		{
+			title: "Not",
+			query: fabric.Query{
+				Predicate: clause("eq", "knows"),
+				Not:       &fabric.Query{Target: clause("eq", "Bob")},
+			},
+			expected: []int{1},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.title, func(t *testing.T) {
+			var expected []fabric.Triple
+			for _, i := range cs.expected {
+				expected = append(expected, fixture[i])
+			}
+
+			assertQuery(t, fab, cs.query, expected)
+
+			count, err := fab.Count(context.Background(), cs.query)
+			if err != nil {
+				t.Fatalf("unexpected error from Count: %v", err)
+			}
+			if count != len(expected) {
+				t.Errorf("expected count %d, got %d", len(expected), count)
+			}
+		})
+	}
+}
+
+func testErrors(t *testing.T, fab *fabric.Fabric) {
+	cases := []struct {
+		title    string
+		query    fabric.Query
+		expected error
+	}{
+		{
+			title:    "UnsupportedClause",
+			query:    fabric.Query{Source: clause("unknown", "Bob")},
+			expected: fabric.ErrUnsupportedClause,
+		},
+		{
+			title:    "UnsupportedWeightClause",
+			query:    fabric.Query{Weight: clause("like", "1")},
+			expected: fabric.ErrUnsupportedClause,
+		},
+		{
+			title:    "StringClauseOnWeight",
+			query:    fabric.Query{Weight: clause("prefix", "1")},
+			expected: fabric.ErrUnsupportedClause,
+		},
+		{
+			title:    "InvalidRegex",
+			query:    fabric.Query{Target: clause("regex", "(")},
+			expected: fabric.ErrInvalidClause,
+		},
+		{
+			title:    "NonNumericWeight",
+			query:    fabric.Query{Weight: clause("gt", "heavy")},
+			expected: fabric.ErrInvalidClause,
+		},
+		{
+			title:    "NonNumericWeightInList",
+			query:    fabric.Query{Weight: clause("in", "1,heavy")},
+			expected: fabric.ErrInvalidClause,
+		},
+		{
+			title:    "InvalidOrder",
+			query:    fabric.Query{OrderBy: []fabric.Order{{Field: "name"}}},
+			expected: fabric.ErrInvalidQuery,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.title, func(t *testing.T) {
+			_, err := fab.Query(context.Background(), cs.query)
+			if !errors.Is(err, cs.expected) {
+				t.Errorf("expecting error '%v', got %v", cs.expected, err)
+			}
+		})
+	}
+}
+
+func testLimit(t *testing.T, fab *fabric.Fabric) {
+	assertQuery(t, fab, fabric.Query{Limit: 2}, fixture[:2])
+
+	assertQuery(t, fab, fabric.Query{
+		Limit:   2,
+		OrderBy: []fabric.Order{{Field: "weight", Desc: true}},
+	}, []fabric.Triple{fixture[3], fixture[2]})
+
+	assertQuery(t, fab, fabric.Query{
+		Predicate: clause("eq", "knows"),
+		Limit:     2,
+		OrderBy:   []fabric.Order{{Field: "target"}},
+	}, []fabric.Triple{fixture[0], fixture[5]})
+
+	// offset applies to the first page only when following the cursors.
+	var pages [][]fabric.Triple
+	query := fabric.Query{Offset: 1, Limit: 2}
+	for {
+		page, err := fab.QueryPage(context.Background(), query)
+		if err != nil {
+			t.Fatalf("unexpected error from QueryPage: %v", err)
+		}
+		pages = append(pages, page.Triples)
+
+		if page.Next == "" || len(pages) > len(fixture) {
+			break
+		}
+		query.Cursor = page.Next
+	}
+
+	expected := [][]fabric.Triple{fixture[1:3], fixture[3:5], fixture[5:]}
+	if !reflect.DeepEqual(expected, pages) {
+		t.Errorf("expected pages %v, got %v", expected, pages)
+	}
+
+	count, err := fab.Count(context.Background(), fabric.Query{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error from Count: %v", err)
+	}
+	if count != len(fixture) {
+		t.Errorf("expected Count to ignore limit and return %d, got %d", len(fixture), count)
+	}
+}
+
+func testDelete(t *testing.T, fab *fabric.Fabric) {
+	ctx := context.Background()
+
+	if _, err := fab.Delete(ctx, fabric.Query{}); !errors.Is(err, fabric.ErrInvalidQuery) {
+		t.Errorf("expecting ErrInvalidQuery when deleting without clauses, got %v", err)
+	}
+
+	deleted, err := fab.Delete(ctx, fabric.Query{Source: clause("eq", "nobody")})
+	if err != nil || deleted != 0 {
+		t.Errorf("expected 0 deletes and no error, got %d and %v", deleted, err)
+	}
+
+	// limit is ignored by delete.
+	deleted, err = fab.Delete(ctx, fabric.Query{Source: clause("like", "dave*"), Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deletes, got %d", deleted)
+	}
+
+	assertQuery(t, fab, fabric.Query{}, []fabric.Triple{fixture[0], fixture[1], fixture[2], fixture[5]})
+}
+
+func testReWeight(t *testing.T, fab *fabric.Fabric) {
+	ctx := context.Background()
+
+	updated, err := fab.ReWeight(ctx, fabric.Query{Predicate: clause("eq", "knows"), Limit: 1}, 1, false)
+	if errors.Is(err, fabric.ErrNotSupported) {
+		t.Skip("store does not implement fabric.ReWeighter")
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated != 3 {
+		t.Errorf("expected 3 updates, got %d", updated)
+	}
+
+	updated, err = fab.ReWeight(ctx, fabric.Query{Source: clause("eq", "Bob")}, 10, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("expected 2 updates, got %d", updated)
+	}
+
+	expected := append([]fabric.Triple{}, fixture...)
+	expected[0].Weight = 2
+	expected[1].Weight = 10
+	expected[2].Weight = 10
+	expected[5].Weight = 1.5
+	assertQuery(t, fab, fabric.Query{}, expected)
+}
+
+func testReturner(t *testing.T, store fabric.Store) {
+	ctx := context.Background()
+
+	ret, ok := store.(fabric.Returner)
+	if !ok {
+		t.Skip("store does not implement fabric.Returner")
+	}
+
+	updated, err := ret.ReWeightReturning(ctx, fabric.Query{Source: clause("eq", "Bob"), Limit: 1}, 1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortTriples(updated)
+
+	expected := []fabric.Triple{fixture[1], fixture[2]}
+	expected[0].Weight = 3
+	expected[1].Weight = 4
+	if !reflect.DeepEqual(expected, updated) {
+		t.Errorf("expected updated triples %v, got %v", expected, updated)
+	}
+
+	deleted, err := ret.DeleteReturning(ctx, fabric.Query{Source: clause("like", "dave*")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sortTriples(deleted)
+	if !reflect.DeepEqual([]fabric.Triple{fixture[3], fixture[4]}, deleted) {
+		t.Errorf("expected deleted triples %v, got %v", fixture[3:5], deleted)
+	}
+
+	if _, err := ret.DeleteReturning(ctx, fabric.Query{}); !errors.Is(err, fabric.ErrInvalidQuery) {
+		t.Errorf("expecting ErrInvalidQuery when deleting without clauses, got %v", err)
+	}
+
+	assertQuery(t, fabric.New(store), fabric.Query{}, []fabric.Triple{fixture[0], expected[0], expected[1], fixture[5]})
+}
+
+// withProps are inserted in addition to the fixture for testing properties.
+var withProps = []fabric.Triple{
+	{Source: "Paul", Predicate: "knows", Target: "Quinn", Weight: 1, Properties: map[string]interface{}{
+		"since": 2015.0, "via": "work", "verified": true,
+	}},
+	{Source: "Paul", Predicate: "knows", Target: "Rita", Weight: 1, Properties: map[string]interface{}{
+		"since": 2020.5, "via": "school",
+	}},
+	{Source: "Paul", Predicate: "knows", Target: "Sam", Weight: 1, Properties: map[string]interface{}{
+		"since": "unknown", "verified": false,
+	}},
+}
+
+func testProperties(t *testing.T, fab *fabric.Fabric) {
+	ctx := context.Background()
+	if _, err := fab.InsertMany(ctx, withProps); err != nil {
+		t.Fatalf("failed to insert triples with properties: %v", err)
+	}
+
+	assertQuery(t, fab, fabric.Query{Source: clause("eq", "Paul")}, withProps)
+
+	cases := []struct {
+		title    string
+		props    map[string]fabric.Clause
+		expected []int
+	}{
+		{
+			title:    "Exists",
+			props:    map[string]fabric.Clause{"verified": {}},
+			expected: []int{0, 2},
+		},
+		{
+			title:    "StringEq",
+			props:    map[string]fabric.Clause{"via": clause("eq", "school")},
+			expected: []int{1},
+		},
+		{
+			title:    "NeqRequiresProperty",
+			props:    map[string]fabric.Clause{"via": clause("neq", "school")},
+			expected: []int{0},
+		},
+		{
+			title:    "BoolEq",
+			props:    map[string]fabric.Clause{"verified": clause("eq", "true")},
+			expected: []int{0},
+		},
+		{
+			title:    "NumberEq",
+			props:    map[string]fabric.Clause{"since": clause("eq", "2015")},
+			expected: []int{0},
+		},
+		{
+			title:    "NumberGtSkipsStrings",
+			props:    map[string]fabric.Clause{"since": clause("gt", "2000")},
+			expected: []int{0, 1},
+		},
+		{
+			title:    "NumberLte",
+			props:    map[string]fabric.Clause{"since": clause("lte", "2015")},
+			expected: []int{0},
+		},
+		{
+			title:    "Like",
+			props:    map[string]fabric.Clause{"via": clause("like", "W*")},
+			expected: []int{0},
+		},
+		{
+			title:    "In",
+			props:    map[string]fabric.Clause{"since": clause("in", "2020.5,unknown")},
+			expected: []int{1, 2},
+		},
+		{
+			title: "MultipleProperties",
+			props: map[string]fabric.Clause{
+				"since":    clause("gte", "2015"),
+				"verified": clause("eq", "true"),
+			},
+			expected: []int{0},
+		},
+		{
+			title:    "Missing",
+			props:    map[string]fabric.Clause{"rating": clause("gt", "0")},
+			expected: nil,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.title, func(t *testing.T) {
+			var expected []fabric.Triple
+			for _, i := range cs.expected {
+				expected = append(expected, withProps[i])
+			}
+
+			assertQuery(t, fab, fabric.Query{Properties: cs.props}, expected)
+		})
+	}
+
+	t.Run("InvalidNumber", func(t *testing.T) {
+		_, err := fab.Query(ctx, fabric.Query{
+			Properties: map[string]fabric.Clause{"since": clause("gt", "recent")},
+		})
+		if !errors.Is(err, fabric.ErrInvalidClause) {
+			t.Errorf("expecting ErrInvalidClause, got %v", err)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		tri := withProps[0]
+		tri.Properties = map[string]interface{}{"via": "club"}
+
+		err := fab.Upsert(ctx, tri, fabric.KeepExisting)
+		if errors.Is(err, fabric.ErrNotSupported) {
+			t.Skip("store does not implement fabric.Upserter")
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertQuery(t, fab, fabric.Query{Properties: map[string]fabric.Clause{
+			"via": clause("eq", "work"),
+		}}, withProps[:1])
+
+		if err := fab.Upsert(ctx, tri, fabric.ReplaceWeight); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertQuery(t, fab, fabric.Query{Properties: map[string]fabric.Clause{
+			"via": clause("eq", "club"),
+		}}, []fabric.Triple{tri})
+		assertQuery(t, fab, fabric.Query{Properties: map[string]fabric.Clause{
+			"since": {},
+		}}, withProps[1:])
+
+		tri.Properties = nil
+		tri.Weight = 2
+		if err := fab.Upsert(ctx, tri, fabric.ReplaceWeight); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tri.Properties = map[string]interface{}{"via": "club"}
+		assertQuery(t, fab, fabric.Query{Properties: map[string]fabric.Clause{
+			"via": clause("eq", "club"),
+		}}, []fabric.Triple{tri})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if _, err := fab.Delete(ctx, fabric.Query{Target: clause("eq", "Rita")}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertQuery(t, fab, fabric.Query{Properties: map[string]fabric.Clause{
+			"via": clause("eq", "school"),
+		}}, nil)
+	})
+}
+
+// literals are inserted in addition to the fixture for testing range
+// clauses on literal targets.
+var literals = []fabric.Triple{
+	{Source: "Erin", Predicate: "age", Target: fabric.Literal("42", fabric.Int).String()},
+	{Source: "Erin", Predicate: "born", Target: fabric.Literal("1980-02-29", fabric.Date).String()},
+	{Source: "Erin", Predicate: "height", Target: fabric.Literal("1.72", fabric.Float).String()},
+	{Source: "Erin", Predicate: "name", Target: fabric.LangLiteral("Erin \"E\" Smith", "en").String()},
+	{Source: "Erin", Predicate: "verified", Target: fabric.Literal("true", fabric.Bool).String()},
+	{Source: "Frank", Predicate: "age", Target: fabric.Literal("7", fabric.Int).String()},
+}
+
+func testLiterals(t *testing.T, fab *fabric.Fabric) {
+	ctx := context.Background()
+	for _, tri := range literals {
+		if err := fab.Insert(ctx, tri); err != nil {
+			t.Fatalf("failed to insert '%s': %v", tri, err)
+		}
+	}
+
+	assertQuery(t, fab, fabric.Query{Source: clause("in", "Erin,Frank")}, literals)
+
+	// literal values are canonicalized and distinct from entities.
+	err := fab.Insert(ctx, fabric.Triple{Source: "Frank", Predicate: "age", Target: `"07"^^int`})
+	if !errors.Is(err, fabric.ErrConflict) {
+		t.Errorf("expecting ErrConflict for non-canonical literal, got %v", err)
+	}
+
+	if err := fab.Insert(ctx, fabric.Triple{Source: "Frank", Predicate: "age", Target: "7"}); err != nil {
+		t.Errorf("unexpected error inserting entity target: %v", err)
+	}
+
+	cases := []struct {
+		title    string
+		query    fabric.Query
+		expected []int
+	}{
+		{
+			title:    "NumberGt",
+			query:    fabric.Query{Target: clause("gt", "10")},
+			expected: []int{0},
+		},
+		{
+			title:    "NumberLte",
+			query:    fabric.Query{Target: clause("<=", "7")},
+			expected: []int{2, 5},
+		},
+		{
+			title:    "TypedBound",
+			query:    fabric.Query{Target: clause("gte", `"1.72"^^float`)},
+			expected: []int{0, 2, 5},
+		},
+		{
+			title:    "DateLt",
+			query:    fabric.Query{Target: clause("lt", "2000-01-01")},
+			expected: []int{1},
+		},
+		{
+			title:    "DateGt",
+			query:    fabric.Query{Target: clause("gt", `"1980-02-29"^^date`)},
+			expected: nil,
+		},
+		{
+			title: "EqOnEncodedForm",
+			query: fabric.Query{
+				Target: clause("eq", fabric.Literal("true", fabric.Bool).String()),
+			},
+			expected: []int{4},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.title, func(t *testing.T) {
+			var expected []fabric.Triple
+			for _, i := range cs.expected {
+				expected = append(expected, literals[i])
+			}
+
+			assertQuery(t, fab, cs.query, expected)
+		})
+	}
+
+	t.Run("InvalidBound", func(t *testing.T) {
+		for _, v := range []string{"old", `"x"@en`, `"true"^^bool`} {
+			_, err := fab.Query(ctx, fabric.Query{Target: clause("gt", v)})
+			if !errors.Is(err, fabric.ErrInvalidClause) {
+				t.Errorf("expecting ErrInvalidClause for '%s', got %v", v, err)
+			}
+		}
+	})
+}
+
+func assertQuery(t *testing.T, fab *fabric.Fabric, query fabric.Query, expected []fabric.Triple) {
+	t.Helper()
+
+	got, err := fab.Query(context.Background(), query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(expected) == 0 && len(got) == 0 {
+		return
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("query %v: expected %v, got %v", query.Map(), expected, got)
+	}
+}
+
+// sortTriples sorts the triples in the default order of query results.
+func sortTriples(triples []fabric.Triple) {
+	sort.Slice(triples, func(i, j int) bool {
+		a, b := triples[i], triples[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Predicate != b.Predicate {
+			return a.Predicate < b.Predicate
+		}
+		return a.Target < b.Target
+	})
+}
+
+func clause(typ, value string) fabric.Clause {
+	return fabric.Clause{Type: typ, Value: value}
+}
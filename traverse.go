@@ -0,0 +1,204 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Direction represents the direction in which edges are followed.
+type Direction int
+
+// Directions in which edges can be followed during traversal.
+const (
+	Outgoing Direction = iota
+	Incoming
+	Both
+)
+
+// ParseDirection parses one of 'out', 'in' or 'both' into a Direction.
+func ParseDirection(s string) (Direction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "out", "outgoing":
+		return Outgoing, nil
+
+	case "in", "incoming":
+		return Incoming, nil
+
+	case "both":
+		return Both, nil
+	}
+
+	return Outgoing, fmt.Errorf("invalid direction '%s'", s)
+}
+
+func (d Direction) String() string {
+	switch d {
+	case Incoming:
+		return "in"
+
+	case Both:
+		return "both"
+	}
+	return "out"
+}
+
+// TraverseOptions can be used to control the traversal done by Traverse.
+type TraverseOptions struct {
+	// Depth is the maximum number of hops from the start node. Defaults to 1.
+	Depth int `json:"depth,omitempty"`
+
+	// Direction in which edges are followed. Defaults to Outgoing.
+	Direction Direction `json:"direction,omitempty"`
+
+	// Predicates restricts the edges that are followed. If empty, edges with
+	// any predicate are followed.
+	Predicates []string `json:"predicates,omitempty"`
+
+	// Weight clause every followed edge must satisfy (e.g., gte 0.5).
+	Weight Clause `json:"weight,omitempty"`
+}
+
+// Traverse walks the edges starting at the given node up to the configured
+// number of hops and returns the induced subgraph of the reached nodes, i.e.,
+// the edges that were traversed along with all the other edges (satisfying
+// the predicate and weight restrictions) between any two reached nodes. Every
+// edge is returned at most once.
+func (f *Fabric) Traverse(ctx context.Context, start string, opts TraverseOptions) ([]Triple, error) {
+	if strings.TrimSpace(start) == "" {
+		return nil, errors.New("start node must be specified")
+	}
+
+	if opts.Depth < 0 {
+		return nil, errors.New("depth must not be negative")
+	} else if opts.Depth == 0 {
+		opts.Depth = 1
+	}
+	opts.Weight.normalize()
+
+	el := edgeLister{
+		store:      f.store,
+		direction:  opts.Direction,
+		predicates: opts.Predicates,
+		weight:     opts.Weight,
+	}
+
+	visited := map[string]bool{start: true}
+	reached := []string{start}
+	seen := map[string]bool{}
+	triples := []Triple{}
+
+	frontier := []string{start}
+	for hop := 0; hop < opts.Depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, node := range frontier {
+			edges, err := el.edges(ctx, node)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, e := range edges {
+				id := fmt.Sprintf("%s %s %s", e.tri.Source, e.tri.Predicate, e.tri.Target)
+				if !seen[id] {
+					seen[id] = true
+					triples = append(triples, e.tri)
+				}
+
+				if !visited[e.next] {
+					visited[e.next] = true
+					reached = append(reached, e.next)
+					next = append(next, e.next)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	// add the edges between reached nodes that were not on a traversal path.
+	// every such edge is an outgoing edge of a reached node.
+	el.direction = Outgoing
+	for _, node := range reached {
+		edges, err := el.edges(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range edges {
+			id := fmt.Sprintf("%s %s %s", e.tri.Source, e.tri.Predicate, e.tri.Target)
+			if visited[e.next] && !seen[id] {
+				seen[id] = true
+				triples = append(triples, e.tri)
+			}
+		}
+	}
+
+	return triples, nil
+}
+
+// edgeLister lists the edges adjacent to a node that satisfy the predicate
+// and weight restrictions.
+type edgeLister struct {
+	store      Store
+	direction  Direction
+	predicates []string
+	weight     Clause
+}
+
+type edge struct {
+	next string
+	tri  Triple
+}
+
+func (el edgeLister) edges(ctx context.Context, node string) ([]edge, error) {
+	var edges []edge
+
+	if el.direction == Outgoing || el.direction == Both {
+		out, err := el.store.Query(ctx, el.query(Query{Source: Clause{Type: "eq", Value: node}}))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tri := range out {
+			if el.allowed(tri) {
+				edges = append(edges, edge{next: tri.Target, tri: tri})
+			}
+		}
+	}
+
+	if el.direction == Incoming || el.direction == Both {
+		in, err := el.store.Query(ctx, el.query(Query{Target: Clause{Type: "eq", Value: node}}))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tri := range in {
+			if el.allowed(tri) {
+				edges = append(edges, edge{next: tri.Source, tri: tri})
+			}
+		}
+	}
+
+	return edges, nil
+}
+
+func (el edgeLister) query(q Query) Query {
+	if len(el.predicates) == 1 {
+		q.Predicate = Clause{Type: "eq", Value: el.predicates[0]}
+	}
+	q.Weight = el.weight
+	return q
+}
+
+func (el edgeLister) allowed(tri Triple) bool {
+	if len(el.predicates) == 0 {
+		return true
+	}
+
+	for _, p := range el.predicates {
+		if tri.Predicate == p {
+			return true
+		}
+	}
+	return false
+}
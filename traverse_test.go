@@ -0,0 +1,99 @@
+package fabric_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_Traverse(suite *testing.T) {
+	suite.Parallel()
+
+	fab := fabric.New(&fabric.InMemoryStore{})
+	for _, tri := range []fabric.Triple{
+		{Source: "a", Predicate: "knows", Target: "b", Weight: 1},
+		{Source: "b", Predicate: "knows", Target: "c", Weight: 1},
+		{Source: "c", Predicate: "knows", Target: "d", Weight: 1},
+		{Source: "a", Predicate: "likes", Target: "e", Weight: 0.1},
+		{Source: "f", Predicate: "knows", Target: "a", Weight: 1},
+		{Source: "c", Predicate: "knows", Target: "a", Weight: 0.2},
+	} {
+		if err := fab.Insert(context.Background(), tri); err != nil {
+			suite.Fatalf("failed to insert: %v", err)
+		}
+	}
+
+	cases := []struct {
+		title     string
+		start     string
+		opts      fabric.TraverseOptions
+		expected  []string
+		expectErr bool
+	}{
+		{
+			title:     "NoStart",
+			expectErr: true,
+		},
+		{
+			title:    "DefaultDepth",
+			start:    "a",
+			expected: []string{"a knows b", "a likes e"},
+		},
+		{
+			title:    "TwoHops",
+			start:    "a",
+			opts:     fabric.TraverseOptions{Depth: 2, Predicates: []string{"knows"}},
+			expected: []string{"a knows b", "b knows c", "c knows a"},
+		},
+		{
+			title:    "Incoming",
+			start:    "b",
+			opts:     fabric.TraverseOptions{Depth: 5, Direction: fabric.Incoming},
+			expected: []string{"a knows b", "b knows c", "c knows a", "f knows a"},
+		},
+		{
+			title:    "Both",
+			start:    "a",
+			opts:     fabric.TraverseOptions{Direction: fabric.Both},
+			expected: []string{"a knows b", "a likes e", "b knows c", "c knows a", "f knows a"},
+		},
+		{
+			title: "WeightThreshold",
+			start: "a",
+			opts: fabric.TraverseOptions{
+				Depth:  10,
+				Weight: fabric.Clause{Type: ">=", Value: "0.5"},
+			},
+			expected: []string{"a knows b", "b knows c", "c knows d"},
+		},
+	}
+
+	for _, cs := range cases {
+		suite.Run(cs.title, func(t *testing.T) {
+			triples, err := fab.Traverse(context.Background(), cs.start, cs.opts)
+			if err != nil {
+				if !cs.expectErr {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if cs.expectErr {
+				t.Fatalf("expecting error, got nil")
+			}
+
+			var edges []string
+			for _, tri := range triples {
+				edges = append(edges, tri.Source+" "+tri.Predicate+" "+tri.Target)
+			}
+			sort.Strings(edges)
+
+			if !reflect.DeepEqual(cs.expected, edges) {
+				t.Errorf("expected %v, got %v", cs.expected, edges)
+			}
+		})
+	}
+}
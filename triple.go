@@ -1,38 +1,133 @@
 package fabric
 
 import (
-	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
-// Triple represents a subject-predicate-object.
+// Triple represents a subject-predicate-object. The target is either the name
+// of an entity or an encoded literal value (see Object).
 type Triple struct {
 	Source    string  `json:"source" yaml:"source" db:"source"`
 	Predicate string  `json:"predicate" yaml:"predicate" db:"predicate"`
 	Target    string  `json:"target" yaml:"target" db:"target"`
 	Weight    float64 `json:"weight" yaml:"weight" db:"weight"` // extension field
+
+	// Properties are arbitrary attributes of the triple (e.g., timestamps,
+	// provenance). Values must be strings, booleans or numbers. Numbers are
+	// stored as float64.
+	Properties map[string]interface{} `json:"properties,omitempty" yaml:"properties,omitempty" db:"properties"`
 }
 
-// Validate ensures the entity names are valid.
+// Validate ensures the entity names are valid. Returns InvalidTripleError
+// describing the first invalid field.
 func (tri Triple) Validate() error {
-	if strings.ContainsAny(tri.Source, forbiddenChars) || tri.Source == "" {
-		return errors.New("invalid source name")
+	fields := [][2]string{
+		{"source", tri.Source},
+		{"predicate", tri.Predicate},
+		{"target", tri.Target},
 	}
 
-	if strings.ContainsAny(tri.Predicate, forbiddenChars) || tri.Predicate == "" {
-		return errors.New("invalid predicate")
+	for _, f := range fields {
+		if f[0] == "target" && strings.HasPrefix(f[1], `"`) {
+			// literal values may contain any character.
+			if _, err := ParseObject(f[1]); err != nil {
+				return &InvalidTripleError{Field: f[0], Value: f[1], Reason: err.Error()}
+			}
+			continue
+		}
+
+		if f[1] == "" {
+			return &InvalidTripleError{Field: f[0], Value: f[1], Reason: "must not be empty"}
+		}
+
+		if strings.ContainsAny(f[1], forbiddenChars) {
+			return &InvalidTripleError{Field: f[0], Value: f[1], Reason: fmt.Sprintf("must not contain any of '%s'", forbiddenChars)}
+		}
 	}
 
-	if strings.ContainsAny(tri.Target, forbiddenChars) || tri.Target == "" {
-		return errors.New("invalid target name")
+	for key, val := range tri.Properties {
+		if key == "" {
+			return &InvalidTripleError{Field: "properties", Value: key, Reason: "property name must not be empty"}
+		}
+
+		if _, ok := propertyValue(val); !ok {
+			return &InvalidTripleError{Field: "properties", Value: key, Reason: fmt.Sprintf("unsupported value type %T", val)}
+		}
 	}
 
 	return nil
 }
 
+// normalize converts literal targets into their canonical form and copies
+// the properties converting all numbers to float64 so that all the stores
+// return identical values. Must be called after Validate.
+func (tri *Triple) normalize() {
+	if obj, err := ParseObject(tri.Target); err == nil && obj.Literal {
+		tri.Target = obj.String()
+	}
+
+	if len(tri.Properties) == 0 {
+		tri.Properties = nil
+		return
+	}
+
+	props := make(map[string]interface{}, len(tri.Properties))
+	for key, val := range tri.Properties {
+		props[key], _ = propertyValue(val)
+	}
+	tri.Properties = props
+}
+
 func (tri Triple) String() string {
 	return fmt.Sprintf("%s %s %s %f", tri.Source, tri.Predicate, tri.Target, tri.Weight)
 }
 
 var forbiddenChars = "? {}()"
+
+// propertyValue returns the normalized form of a property value. Returns
+// false if the type of the value is not supported.
+func propertyValue(val interface{}) (interface{}, bool) {
+	switch v := val.(type) {
+	case string, bool, float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return nil, false
+}
+
+// propertyText returns the string form of a normalized property value which
+// is used for matching clauses other than the numeric comparisons.
+func propertyText(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(val)
+}
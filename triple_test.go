@@ -1,6 +1,7 @@
 package fabric_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/spy16/fabric"
@@ -13,6 +14,7 @@ func TestTriple_Validate(suite *testing.T) {
 		title     string
 		triple    fabric.Triple
 		expectErr bool
+		field     string
 	}{
 		{
 			title: "InvalidSourceName",
@@ -20,6 +22,7 @@ func TestTriple_Validate(suite *testing.T) {
 				Source: "?",
 			},
 			expectErr: true,
+			field:     "source",
 		},
 		{
 			title: "InvalidPredicateName",
@@ -28,6 +31,7 @@ func TestTriple_Validate(suite *testing.T) {
 				Predicate: "",
 			},
 			expectErr: true,
+			field:     "predicate",
 		},
 		{
 			title: "InvalidTarget",
@@ -37,6 +41,57 @@ func TestTriple_Validate(suite *testing.T) {
 				Target:    "{",
 			},
 			expectErr: true,
+			field:     "target",
+		},
+		{
+			title: "InvalidLiteralTarget",
+			triple: fabric.Triple{
+				Source:    "bob",
+				Predicate: "age",
+				Target:    `"forty"^^int`,
+			},
+			expectErr: true,
+			field:     "target",
+		},
+		{
+			title: "LiteralTargetWithSpaces",
+			triple: fabric.Triple{
+				Source:    "bob",
+				Predicate: "name",
+				Target:    `"Bob (Robert) Smith"@en`,
+			},
+		},
+		{
+			title: "InvalidLanguageTag",
+			triple: fabric.Triple{
+				Source:    "bob",
+				Predicate: "age",
+				Target:    `"x"@a"^^int`,
+			},
+			expectErr: true,
+			field:     "target",
+		},
+		{
+			title: "EmptyPropertyName",
+			triple: fabric.Triple{
+				Source:     "bob",
+				Predicate:  "knows",
+				Target:     "john",
+				Properties: map[string]interface{}{"": "x"},
+			},
+			expectErr: true,
+			field:     "properties",
+		},
+		{
+			title: "UnsupportedPropertyValue",
+			triple: fabric.Triple{
+				Source:     "bob",
+				Predicate:  "knows",
+				Target:     "john",
+				Properties: map[string]interface{}{"tags": []string{"a"}},
+			},
+			expectErr: true,
+			field:     "properties",
 		},
 		{
 			title: "Valid",
@@ -46,6 +101,15 @@ func TestTriple_Validate(suite *testing.T) {
 				Target:    "john",
 			},
 		},
+		{
+			title: "ValidProperties",
+			triple: fabric.Triple{
+				Source:     "bob",
+				Predicate:  "knows",
+				Target:     "john",
+				Properties: map[string]interface{}{"since": 2015, "via": "work", "verified": true},
+			},
+		},
 	}
 
 	for _, cs := range cases {
@@ -56,6 +120,15 @@ func TestTriple_Validate(suite *testing.T) {
 					t.Errorf("unexpected error: %v", err)
 					return
 				}
+
+				if !errors.Is(err, fabric.ErrInvalidTriple) {
+					t.Errorf("expecting ErrInvalidTriple, got %v", err)
+				}
+
+				var ite *fabric.InvalidTripleError
+				if !errors.As(err, &ite) || ite.Field != cs.field {
+					t.Errorf("expecting invalid field '%s', got %v", cs.field, err)
+				}
 				return
 			}
 
@@ -0,0 +1,912 @@
+package fabric
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TurtleReader reads triples from a Turtle document. Prefixes, base IRIs,
+// predicate and object lists, blank node property lists ('[...]') and the
+// literal shorthands are supported. Collections ('(...)') are not. See
+// RDFOptions for the mapping of RDF terms onto triples.
+type TurtleReader struct {
+	parser *rdfParser
+}
+
+// NewTurtleReader returns a reader for the Turtle document in r. Prefixes in
+// opts are declared before reading the document.
+func NewTurtleReader(r io.Reader, opts RDFOptions) *TurtleReader {
+	return &TurtleReader{parser: newRDFParser(r, opts, true)}
+}
+
+// Read returns the next triple from the document. Returns io.EOF when there
+// are no more triples. Triples that cannot be converted (e.g., literals of
+// unsupported datatypes) are reported using a LineError and reading can be
+// continued.
+func (tr *TurtleReader) Read() (Triple, error) {
+	return tr.parser.read()
+}
+
+// TurtleWriter writes triples as Turtle statements. Consecutive triples with
+// the same source are grouped into a predicate list. IRIs are abbreviated
+// using the prefixes in RDFOptions along with the 'xsd' prefix and the empty
+// prefix for the base IRI (unless overridden). Writes are buffered and Flush
+// must be called after the last triple.
+type TurtleWriter struct {
+	w        *bufio.Writer
+	opts     RDFOptions
+	prefixes [][2]string
+	started  bool
+	subject  string
+}
+
+// NewTurtleWriter returns a writer writing Turtle to w.
+func NewTurtleWriter(w io.Writer, opts RDFOptions) *TurtleWriter {
+	prefixes := map[string]string{"": opts.base(), "xsd": XSD}
+	for name, ns := range opts.Prefixes {
+		prefixes[name] = ns
+	}
+
+	tw := &TurtleWriter{w: bufio.NewWriter(w), opts: opts}
+	for name, ns := range prefixes {
+		tw.prefixes = append(tw.prefixes, [2]string{name, ns})
+	}
+	sort.Slice(tw.prefixes, func(i, j int) bool {
+		return tw.prefixes[i][0] < tw.prefixes[j][0]
+	})
+
+	return tw
+}
+
+// Write writes the triple as a Turtle statement.
+func (tw *TurtleWriter) Write(tri Triple) error {
+	subject, object, err := tw.opts.terms(tri)
+	if err != nil {
+		return err
+	}
+
+	predicate := "a"
+	if iri := tw.opts.iri(tri.Predicate); iri != RDF+"type" {
+		predicate = tw.term(rdfTerm{kind: termIRI, value: iri})
+	}
+
+	tw.header()
+
+	s := tw.term(subject)
+	if s == tw.subject {
+		_, err = tw.w.WriteString(" ;\n    " + predicate + " " + tw.term(object))
+		return err
+	}
+
+	if tw.subject != "" {
+		tw.w.WriteString(" .\n")
+	}
+	tw.subject = s
+
+	_, err = tw.w.WriteString(s + " " + predicate + " " + tw.term(object))
+	return err
+}
+
+// Flush terminates the last statement and writes any buffered data to the
+// underlying writer.
+func (tw *TurtleWriter) Flush() error {
+	tw.header()
+
+	if tw.subject != "" {
+		tw.w.WriteString(" .\n")
+		tw.subject = ""
+	}
+	return tw.w.Flush()
+}
+
+func (tw *TurtleWriter) header() {
+	if tw.started {
+		return
+	}
+	tw.started = true
+
+	for _, p := range tw.prefixes {
+		fmt.Fprintf(tw.w, "@prefix %s: <%s> .\n", p[0], p[1])
+	}
+	tw.w.WriteString("\n")
+}
+
+// term returns the Turtle form of the term.
+func (tw *TurtleWriter) term(term rdfTerm) string {
+	switch term.kind {
+	case termIRI:
+		return tw.abbreviate(term.value)
+
+	case termLiteral:
+		switch term.datatype {
+		case XSD + "integer", XSD + "boolean":
+			return term.value
+
+		case "":
+			return ntTerm(term)
+		}
+		return quoteLiteral(term.value) + "^^" + tw.abbreviate(term.datatype)
+	}
+
+	return ntTerm(term)
+}
+
+// abbreviate returns the prefixed name for the IRI if possible.
+func (tw *TurtleWriter) abbreviate(iri string) string {
+	best := -1
+	for i, p := range tw.prefixes {
+		ns := p[1]
+		if !strings.HasPrefix(iri, ns) || !isTurtleLocalName(iri[len(ns):]) {
+			continue
+		}
+
+		if best < 0 || len(ns) > len(tw.prefixes[best][1]) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return "<" + iri + ">"
+	}
+
+	p := tw.prefixes[best]
+	return p[0] + ":" + iri[len(p[1]):]
+}
+
+// isTurtleLocalName returns true if s can be used as the local part of a
+// prefixed name without escaping.
+func isTurtleLocalName(s string) bool {
+	if s == "" || s[0] == '-' || s[0] == '.' || s[len(s)-1] == '.' {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_-.:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// rdfParser parses N-Triples and Turtle documents. Since N-Triples is a
+// subset of Turtle, the same parser is used with the Turtle specific syntax
+// disabled.
+type rdfParser struct {
+	rd       *bufio.Reader
+	opts     RDFOptions
+	turtle   bool
+	line     int
+	base     string
+	prefixes map[string]string
+	pending  []rdfRecord
+	peeked   *rdfToken
+	anon     int
+	lastNL   bool
+}
+
+// Kinds of tokens in N-Triples and Turtle documents.
+const (
+	rdfEOF = iota
+	rdfIRI
+	rdfPName
+	rdfBlank
+	rdfString
+	rdfLang
+	rdfCaret
+	rdfNumber
+	rdfWord
+	rdfPunct
+)
+
+// rdfRecord is a triple read from the document or the error converting the
+// statement into a triple.
+type rdfRecord struct {
+	tri Triple
+	err error
+}
+
+type rdfToken struct {
+	kind int
+	text string
+	line int
+}
+
+func newRDFParser(r io.Reader, opts RDFOptions, turtle bool) *rdfParser {
+	p := &rdfParser{
+		rd:       bufio.NewReader(r),
+		opts:     opts,
+		turtle:   turtle,
+		line:     1,
+		prefixes: map[string]string{},
+	}
+
+	for name, ns := range opts.Prefixes {
+		p.prefixes[name] = ns
+	}
+
+	if p.opts.BlankNodePrefix == "" {
+		p.opts.BlankNodePrefix = newBlankNodePrefix()
+	}
+	return p
+}
+
+func (p *rdfParser) read() (Triple, error) {
+	for len(p.pending) == 0 {
+		if err := p.statement(); err != nil {
+			return Triple{}, err
+		}
+	}
+
+	rec := p.pending[0]
+	p.pending = p.pending[1:]
+	return rec.tri, rec.err
+}
+
+// statement parses the next directive or triples statement.
+func (p *rdfParser) statement() error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case tok.kind == rdfEOF:
+		return io.EOF
+
+	case p.turtle && tok.kind == rdfLang && (tok.text == "prefix" || tok.text == "base"):
+		return p.directive(tok.text, true)
+
+	case p.turtle && tok.kind == rdfWord && (strings.EqualFold(tok.text, "prefix") || strings.EqualFold(tok.text, "base")):
+		return p.directive(strings.ToLower(tok.text), false)
+	}
+
+	subject, err := p.subject(tok)
+	if err != nil {
+		return err
+	}
+
+	// a blank node property list may be a statement by itself.
+	if tok.kind != rdfPunct || tok.text != "[" || !p.peekPunct(".") {
+		if err := p.predicateObjectList(subject); err != nil {
+			return err
+		}
+	}
+
+	return p.expectPunct(".")
+}
+
+func (p *rdfParser) directive(name string, dot bool) error {
+	if name == "prefix" {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if tok.kind != rdfPName || !strings.HasSuffix(tok.text, ":") || strings.Count(tok.text, ":") != 1 {
+			return p.errorf(tok, "expecting a prefix name, got '%s'", tok.text)
+		}
+
+		ns, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if ns.kind != rdfIRI {
+			return p.errorf(ns, "expecting an IRI, got '%s'", ns.text)
+		}
+		p.prefixes[strings.TrimSuffix(tok.text, ":")] = p.resolve(ns.text)
+	} else {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if tok.kind != rdfIRI {
+			return p.errorf(tok, "expecting an IRI, got '%s'", tok.text)
+		}
+		p.base = p.resolve(tok.text)
+	}
+
+	if dot {
+		return p.expectPunct(".")
+	}
+	return nil
+}
+
+func (p *rdfParser) subject(tok rdfToken) (rdfTerm, error) {
+	switch {
+	case tok.kind == rdfIRI, tok.kind == rdfPName:
+		return p.iri(tok)
+
+	case tok.kind == rdfBlank:
+		return rdfTerm{kind: termBlank, value: tok.text}, nil
+
+	case tok.kind == rdfPunct && tok.text == "[":
+		return p.blankNodePropertyList()
+	}
+
+	return rdfTerm{}, p.errorf(tok, "expecting a subject, got '%s'", tok.text)
+}
+
+func (p *rdfParser) predicateObjectList(subject rdfTerm) error {
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		var predicate rdfTerm
+		switch {
+		case tok.kind == rdfIRI, tok.kind == rdfPName:
+			if predicate, err = p.iri(tok); err != nil {
+				return err
+			}
+
+		case tok.kind == rdfWord && tok.text == "a":
+			predicate = rdfTerm{kind: termIRI, value: RDF + "type"}
+
+		default:
+			return p.errorf(tok, "expecting a predicate, got '%s'", tok.text)
+		}
+
+		if err := p.objectList(subject, predicate); err != nil {
+			return err
+		}
+
+		if !p.peekPunct(";") {
+			return nil
+		}
+
+		for p.peekPunct(";") {
+			p.next()
+		}
+
+		// a trailing ';' is allowed.
+		if p.peekPunct(".") || p.peekPunct("]") {
+			return nil
+		}
+	}
+}
+
+func (p *rdfParser) objectList(subject, predicate rdfTerm) error {
+	for {
+		object, err := p.object()
+		if err != nil {
+			return err
+		}
+
+		// the statement is still well-formed. so, a triple that cannot be
+		// converted fails on its own and the parsing continues.
+		tri, err := p.opts.toTriple(subject, predicate, object)
+		if err != nil {
+			err = &LineError{Line: p.line, Err: err}
+		}
+		p.pending = append(p.pending, rdfRecord{tri: tri, err: err})
+
+		if !p.peekPunct(",") {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *rdfParser) object() (rdfTerm, error) {
+	tok, err := p.next()
+	if err != nil {
+		return rdfTerm{}, err
+	}
+
+	switch tok.kind {
+	case rdfIRI, rdfPName:
+		return p.iri(tok)
+
+	case rdfBlank:
+		return rdfTerm{kind: termBlank, value: tok.text}, nil
+
+	case rdfString:
+		return p.literal(tok)
+
+	case rdfNumber:
+		dt := "integer"
+		if strings.ContainsAny(tok.text, "eE") {
+			dt = "double"
+		} else if strings.Contains(tok.text, ".") {
+			dt = "decimal"
+		}
+		return rdfTerm{kind: termLiteral, value: tok.text, datatype: XSD + dt}, nil
+
+	case rdfWord:
+		if tok.text == "true" || tok.text == "false" {
+			return rdfTerm{kind: termLiteral, value: tok.text, datatype: XSD + "boolean"}, nil
+		}
+
+	case rdfPunct:
+		if tok.text == "[" {
+			return p.blankNodePropertyList()
+		}
+
+		if tok.text == "(" {
+			return rdfTerm{}, p.errorf(tok, "collections are not supported")
+		}
+	}
+
+	return rdfTerm{}, p.errorf(tok, "expecting an object, got '%s'", tok.text)
+}
+
+func (p *rdfParser) literal(tok rdfToken) (rdfTerm, error) {
+	term := rdfTerm{kind: termLiteral, value: tok.text}
+
+	next, err := p.peek()
+	if err != nil {
+		return rdfTerm{}, err
+	}
+
+	switch next.kind {
+	case rdfLang:
+		p.next()
+		term.lang = strings.ToLower(next.text)
+
+	case rdfCaret:
+		p.next()
+		dt, err := p.next()
+		if err != nil {
+			return rdfTerm{}, err
+		}
+
+		if dt.kind != rdfIRI && dt.kind != rdfPName {
+			return rdfTerm{}, p.errorf(dt, "expecting a datatype IRI, got '%s'", dt.text)
+		}
+
+		iri, err := p.iri(dt)
+		if err != nil {
+			return rdfTerm{}, err
+		}
+		term.datatype = iri.value
+	}
+
+	return term, nil
+}
+
+func (p *rdfParser) blankNodePropertyList() (rdfTerm, error) {
+	p.anon++
+	node := rdfTerm{kind: termBlank, value: fmt.Sprintf("anon%d", p.anon)}
+
+	if p.peekPunct("]") {
+		p.next()
+		return node, nil
+	}
+
+	if err := p.predicateObjectList(node); err != nil {
+		return rdfTerm{}, err
+	}
+
+	return node, p.expectPunct("]")
+}
+
+// iri returns the IRI term for the IRI or prefixed name token.
+func (p *rdfParser) iri(tok rdfToken) (rdfTerm, error) {
+	if tok.kind == rdfIRI {
+		return rdfTerm{kind: termIRI, value: p.resolve(tok.text)}, nil
+	}
+
+	i := strings.IndexByte(tok.text, ':')
+	ns, found := p.prefixes[tok.text[:i]]
+	if !found {
+		return rdfTerm{}, p.errorf(tok, "undefined prefix '%s'", tok.text[:i])
+	}
+
+	return rdfTerm{kind: termIRI, value: ns + tok.text[i+1:]}, nil
+}
+
+// resolve resolves the IRI relative to the base IRI (if any).
+func (p *rdfParser) resolve(iri string) string {
+	if p.base == "" || isAbsoluteIRI(iri) {
+		return iri
+	}
+
+	base := p.base
+	if i := strings.IndexByte(base, '#'); i >= 0 {
+		base = base[:i]
+	}
+
+	switch {
+	case iri == "":
+		return base
+
+	case strings.HasPrefix(iri, "#"):
+		return base + iri
+
+	case strings.HasPrefix(iri, "//"):
+		return base[:strings.IndexByte(base, ':')+1] + iri
+
+	case strings.HasPrefix(iri, "/"):
+		if i := strings.Index(base, "//"); i >= 0 {
+			if j := strings.IndexByte(base[i+2:], '/'); j >= 0 {
+				return base[:i+2+j] + iri
+			}
+			return base + iri
+		}
+	}
+
+	if i := strings.LastIndexByte(base, '/'); i >= 0 {
+		return base[:i+1] + iri
+	}
+	return base + iri
+}
+
+func (p *rdfParser) peekPunct(punct string) bool {
+	tok, err := p.peek()
+	return err == nil && tok.kind == rdfPunct && tok.text == punct
+}
+
+func (p *rdfParser) expectPunct(punct string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+
+	if tok.kind != rdfPunct || tok.text != punct {
+		return p.errorf(tok, "expecting '%s', got '%s'", punct, tok.text)
+	}
+	return nil
+}
+
+func (p *rdfParser) peek() (rdfToken, error) {
+	if p.peeked == nil {
+		tok, err := p.lex()
+		if err != nil {
+			return tok, err
+		}
+		p.peeked = &tok
+	}
+	return *p.peeked, nil
+}
+
+func (p *rdfParser) next() (rdfToken, error) {
+	tok, err := p.peek()
+	p.peeked = nil
+	return tok, err
+}
+
+func (p *rdfParser) format() string {
+	if p.turtle {
+		return "turtle"
+	}
+	return "ntriples"
+}
+
+func (p *rdfParser) errorf(tok rdfToken, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: line %d: %s", p.format(), tok.line, fmt.Sprintf(format, args...))
+}
+
+// lex reads the next token from the document.
+func (p *rdfParser) lex() (rdfToken, error) {
+	r, err := p.skipSpace()
+	if err == io.EOF {
+		return rdfToken{kind: rdfEOF, text: "<eof>", line: p.line}, nil
+	} else if err != nil {
+		return rdfToken{}, err
+	}
+
+	tok := rdfToken{line: p.line}
+	switch {
+	case r == '<':
+		tok.kind = rdfIRI
+		tok.text, err = p.lexIRI()
+
+	case r == '"' || (p.turtle && r == '\''):
+		tok.kind = rdfString
+		tok.text, err = p.lexString(r)
+
+	case r == '@':
+		tok.kind = rdfLang
+		tok.text = p.lexWhile(func(r rune) bool { return r == '-' || isASCIIAlnum(r) })
+		if tok.text == "" {
+			err = fmt.Errorf("empty language tag")
+		}
+
+	case r == '^':
+		tok.kind, tok.text = rdfCaret, "^^"
+		if next, _ := p.readRune(); next != '^' {
+			err = fmt.Errorf("expecting '^^'")
+		}
+
+	case r == '_':
+		tok.kind = rdfBlank
+		if next, _ := p.readRune(); next != ':' {
+			err = fmt.Errorf("expecting '_:'")
+			break
+		}
+
+		tok.text = p.lexName()
+		if tok.text == "" {
+			err = fmt.Errorf("empty blank node label")
+		}
+
+	case p.turtle && (r == '+' || r == '-' || (r >= '0' && r <= '9') || (r == '.' && p.peekDigit())):
+		tok.kind = rdfNumber
+		tok.text = string(r) + p.lexNumber()
+		if _, perr := strconv.ParseFloat(tok.text, 64); perr != nil {
+			err = fmt.Errorf("invalid number '%s'", tok.text)
+		}
+
+	case r == '.' || (p.turtle && strings.ContainsRune(";,[]()", r)):
+		tok.kind, tok.text = rdfPunct, string(r)
+
+	case p.turtle && (r == ':' || r == '_' || unicode.IsLetter(r)):
+		p.unreadRune()
+		tok.text = p.lexName()
+		tok.kind = rdfWord
+		if strings.ContainsRune(tok.text, ':') {
+			tok.kind = rdfPName
+		}
+
+	default:
+		err = fmt.Errorf("unexpected '%c'", r)
+	}
+
+	if err != nil {
+		return rdfToken{}, fmt.Errorf("%s: line %d: %v", p.format(), tok.line, err)
+	}
+	return tok, nil
+}
+
+// skipSpace skips white spaces and comments and returns the next rune.
+func (p *rdfParser) skipSpace() (rune, error) {
+	for {
+		r, err := p.readRune()
+		if err != nil {
+			return 0, err
+		}
+
+		if r == '#' {
+			for r != '\n' {
+				if r, err = p.readRune(); err != nil {
+					return 0, err
+				}
+			}
+			continue
+		}
+
+		if !unicode.IsSpace(r) {
+			return r, nil
+		}
+	}
+}
+
+func (p *rdfParser) lexIRI() (string, error) {
+	var sb strings.Builder
+	for {
+		r, err := p.readRune()
+		if err != nil {
+			return "", fmt.Errorf("unterminated IRI")
+		}
+
+		switch {
+		case r == '>':
+			return sb.String(), nil
+
+		case r == '\\':
+			esc, err := p.readRune()
+			if err != nil || (esc != 'u' && esc != 'U') {
+				return "", fmt.Errorf("invalid escape in IRI")
+			}
+
+			u, err := p.lexUnicode(esc)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteRune(u)
+
+		case r <= 0x20 || strings.ContainsRune(`<"{}|^`+"`", r):
+			return "", fmt.Errorf("invalid character %q in IRI", r)
+
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (p *rdfParser) lexString(quote rune) (string, error) {
+	long := false
+	if p.turtle {
+		if b, err := p.rd.Peek(2); err == nil && rune(b[0]) == quote && rune(b[1]) == quote {
+			p.readRune()
+			p.readRune()
+			long = true
+		} else if err == nil && rune(b[0]) == quote {
+			// empty string.
+			p.readRune()
+			return "", nil
+		}
+	}
+
+	var sb strings.Builder
+	for {
+		r, err := p.readRune()
+		if err != nil {
+			return "", fmt.Errorf("unterminated string")
+		}
+
+		switch {
+		case r == quote && !long:
+			return sb.String(), nil
+
+		case r == quote:
+			if b, err := p.rd.Peek(2); err == nil && rune(b[0]) == quote && rune(b[1]) == quote {
+				p.readRune()
+				p.readRune()
+				return sb.String(), nil
+			}
+			sb.WriteRune(r)
+
+		case r == '\\':
+			esc, err := p.readRune()
+			if err != nil {
+				return "", fmt.Errorf("unterminated string")
+			}
+
+			switch esc {
+			case 't':
+				sb.WriteByte('\t')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'f':
+				sb.WriteByte('\f')
+			case '"', '\'', '\\':
+				sb.WriteRune(esc)
+			case 'u', 'U':
+				u, err := p.lexUnicode(esc)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteRune(u)
+			default:
+				return "", fmt.Errorf("invalid escape '\\%c' in string", esc)
+			}
+
+		case (r == '\n' || r == '\r') && !long:
+			return "", fmt.Errorf("unterminated string")
+
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (p *rdfParser) lexUnicode(esc rune) (rune, error) {
+	n := 4
+	if esc == 'U' {
+		n = 8
+	}
+
+	var hex []rune
+	for i := 0; i < n; i++ {
+		r, err := p.readRune()
+		if err != nil {
+			return 0, fmt.Errorf("invalid unicode escape")
+		}
+		hex = append(hex, r)
+	}
+
+	u, err := strconv.ParseUint(string(hex), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unicode escape '\\%c%s'", esc, string(hex))
+	}
+	return rune(u), nil
+}
+
+// lexName reads a prefixed name, keyword or blank node label. A '.' is part
+// of the name only if it is not the last character.
+func (p *rdfParser) lexName() string {
+	var sb strings.Builder
+	for {
+		r, err := p.readRune()
+		if err != nil {
+			return sb.String()
+		}
+
+		switch {
+		case r == '.':
+			b, err := p.rd.Peek(1)
+			if err != nil || !isNameRune(rune(b[0])) {
+				p.unreadRune()
+				return sb.String()
+			}
+			sb.WriteRune(r)
+
+		case r == '\\' && p.turtle:
+			esc, err := p.readRune()
+			if err != nil {
+				return sb.String()
+			}
+			sb.WriteRune(esc)
+
+		case isNameRune(r) || r == '%':
+			sb.WriteRune(r)
+
+		default:
+			p.unreadRune()
+			return sb.String()
+		}
+	}
+}
+
+func (p *rdfParser) lexNumber() string {
+	var sb strings.Builder
+	for {
+		r, err := p.readRune()
+		if err != nil {
+			return sb.String()
+		}
+
+		if r == '.' && !p.peekDigit() {
+			p.unreadRune()
+			return sb.String()
+		}
+
+		if !strings.ContainsRune("0123456789.eE+-", r) {
+			p.unreadRune()
+			return sb.String()
+		}
+		sb.WriteRune(r)
+	}
+}
+
+func (p *rdfParser) lexWhile(fn func(r rune) bool) string {
+	var sb strings.Builder
+	for {
+		r, err := p.readRune()
+		if err != nil {
+			return sb.String()
+		}
+
+		if !fn(r) {
+			p.unreadRune()
+			return sb.String()
+		}
+		sb.WriteRune(r)
+	}
+}
+
+func (p *rdfParser) peekDigit() bool {
+	b, err := p.rd.Peek(1)
+	return err == nil && b[0] >= '0' && b[0] <= '9'
+}
+
+func (p *rdfParser) readRune() (rune, error) {
+	r, _, err := p.rd.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	p.lastNL = r == '\n'
+	if p.lastNL {
+		p.line++
+	}
+	return r, nil
+}
+
+func (p *rdfParser) unreadRune() {
+	if p.rd.UnreadRune() == nil && p.lastNL {
+		p.line--
+	}
+	p.lastNL = false
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r == '-' || r == ':' || unicode.IsLetter(r) || unicode.IsDigit(r) || r >= 0x80
+}
+
+func isASCIIAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,125 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConflictPolicy decides how an upsert resolves a conflict with an existing
+// triple having the same source, predicate and target.
+type ConflictPolicy int
+
+// Conflict policies supported by Upsert.
+const (
+	// KeepExisting leaves the existing triple unchanged.
+	KeepExisting ConflictPolicy = iota
+
+	// ReplaceWeight sets the weight of the existing triple to the new one.
+	ReplaceWeight
+
+	// AddWeight adds the new weight to the weight of the existing triple.
+	AddWeight
+
+	// MaxWeight keeps the larger of the existing and the new weights.
+	MaxWeight
+)
+
+// ParseConflictPolicy parses one of 'keep', 'replace', 'add' or 'max' into
+// a ConflictPolicy.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	for p, name := range conflictPolicyNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return p, nil
+		}
+	}
+
+	return KeepExisting, fmt.Errorf("invalid conflict policy '%s'", s)
+}
+
+func (p ConflictPolicy) String() string {
+	if name, found := conflictPolicyNames[p]; found {
+		return name
+	}
+	return fmt.Sprintf("ConflictPolicy(%d)", int(p))
+}
+
+// resolve returns the weight to be stored when a triple with weight
+// 'incoming' conflicts with an existing one with weight 'existing'.
+func (p ConflictPolicy) resolve(existing, incoming float64) float64 {
+	switch p {
+	case ReplaceWeight:
+		return incoming
+
+	case AddWeight:
+		return existing + incoming
+
+	case MaxWeight:
+		if incoming > existing {
+			return incoming
+		}
+	}
+
+	return existing
+}
+
+var conflictPolicyNames = map[ConflictPolicy]string{
+	KeepExisting:  "keep",
+	ReplaceWeight: "replace",
+	AddWeight:     "add",
+	MaxWeight:     "max",
+}
+
+// Upsert validates the triple and inserts it into the store. If the triple
+// already exists, the conflict is resolved using the given policy. Unless the
+// policy is KeepExisting or the given triple has no properties, the
+// properties of the existing triple are replaced with the properties of the
+// given triple. Returns ErrNotSupported if the store does not implement the
+// Upserter interface.
+func (f *Fabric) Upsert(ctx context.Context, tri Triple, policy ConflictPolicy) error {
+	if err := tri.Validate(); err != nil {
+		return err
+	}
+	tri.normalize()
+
+	if _, found := conflictPolicyNames[policy]; !found {
+		return fmt.Errorf("invalid conflict policy '%s'", policy)
+	}
+
+	ups, ok := f.store.(Upserter)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	query := Query{
+		Source:    Clause{Type: "eq", Value: tri.Source},
+		Predicate: Clause{Type: "eq", Value: tri.Predicate},
+		Target:    Clause{Type: "eq", Value: tri.Target},
+	}
+
+	before, err := f.affected(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	if err := ups.Upsert(ctx, tri, policy); err != nil {
+		return err
+	}
+
+	affected, err := f.affected(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	// upserts resolved without changing the stored triple (e.g., with
+	// KeepExisting) are not reported.
+	if reflect.DeepEqual(before, affected) {
+		return nil
+	}
+
+	for _, tri := range affected {
+		f.emit(Event{Type: EventUpsert, Triple: tri})
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package fabric_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_Upsert(suite *testing.T) {
+	suite.Parallel()
+
+	stores := withSQLStore(suite, map[string]func() fabric.Store{
+		"InMemoryStore": func() fabric.Store { return &fabric.InMemoryStore{} },
+	})
+
+	cases := []struct {
+		policy   fabric.ConflictPolicy
+		expected float64
+	}{
+		{policy: fabric.KeepExisting, expected: 2},
+		{policy: fabric.ReplaceWeight, expected: 1},
+		{policy: fabric.AddWeight, expected: 3},
+		{policy: fabric.MaxWeight, expected: 2},
+	}
+
+	for name, newStore := range stores {
+		newStore := newStore
+
+		suite.Run(name, func(t *testing.T) {
+			for _, cs := range cases {
+				t.Run(cs.policy.String(), func(t *testing.T) {
+					fab := fabric.New(newStore())
+					tri := fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John", Weight: 2}
+
+					if err := fab.Upsert(context.Background(), tri, cs.policy); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					tri.Weight = 1
+					if err := fab.Upsert(context.Background(), tri, cs.policy); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					tri.Weight = cs.expected
+					assertTriples(t, fab, []fabric.Triple{tri})
+				})
+			}
+		})
+	}
+
+	suite.Run("NotSupported", func(t *testing.T) {
+		fab := fabric.New(storeOnly{&fabric.InMemoryStore{}})
+		err := fab.Upsert(context.Background(), fabric.Triple{Source: "a", Predicate: "b", Target: "c"}, fabric.KeepExisting)
+		if err != fabric.ErrNotSupported {
+			t.Errorf("expected ErrNotSupported, got %v", err)
+		}
+	})
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, name := range []string{"keep", "replace", "add", "max"} {
+		p, err := fabric.ParseConflictPolicy(name)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		} else if p.String() != name {
+			t.Errorf("expected '%s', got '%s'", name, p)
+		}
+	}
+
+	if _, err := fabric.ParseConflictPolicy("min"); err == nil {
+		t.Errorf("expecting error, got nil")
+	}
+}
@@ -0,0 +1,143 @@
+package fabric
+
+import (
+	"context"
+	"sync"
+)
+
+// Types of events emitted by Watch.
+const (
+	EventInsert   = "insert"
+	EventUpsert   = "upsert"
+	EventDelete   = "delete"
+	EventReWeight = "reweight"
+)
+
+// Event represents a change made to a triple through the Fabric.
+type Event struct {
+	// Type is one of insert, upsert, delete or reweight.
+	Type string `json:"type"`
+
+	// Triple is the triple after the change. For delete events, it is the
+	// triple that was deleted.
+	Triple Triple `json:"triple"`
+
+	// Missed is the number of events that were dropped for the subscriber
+	// before this event because it was not consuming them fast enough.
+	Missed int `json:"missed,omitempty"`
+}
+
+// Watch returns a channel on which events are emitted for every change made
+// through the Fabric to triples matching the query. Events are buffered for
+// every subscriber and are dropped if the buffer is full; the number of such
+// dropped events is reported in the Missed field of the next event that is
+// delivered. The channel is closed when the context is cancelled. Delete and
+// reweight events are exact only if the store implements Returner.
+func (f *Fabric) Watch(ctx context.Context, query Query) (<-chan Event, error) {
+	query.normalize()
+	m, err := compileQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{
+		matcher: m,
+		ch:      make(chan Event, watchBufferSize),
+	}
+	f.watch.add(sub)
+
+	go func() {
+		<-ctx.Done()
+		f.watch.remove(sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// emit publishes the events to the subscribers. Within a batch, events are
+// held back until the batch is committed.
+func (f *Fabric) emit(events ...Event) {
+	if f.pending != nil {
+		*f.pending = append(*f.pending, events...)
+		return
+	}
+
+	for _, ev := range events {
+		f.watch.publish(ev)
+	}
+}
+
+// watching returns true if there are any subscribers for events.
+func (f *Fabric) watching() bool {
+	return f.watch.active()
+}
+
+// watchBufferSize is the number of events buffered for every subscriber.
+const watchBufferSize = 128
+
+type watchHub struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+}
+
+type subscription struct {
+	matcher *matcher
+	ch      chan Event
+
+	mu     sync.Mutex
+	missed int
+}
+
+func (hub *watchHub) add(sub *subscription) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.subs == nil {
+		hub.subs = map[*subscription]struct{}{}
+	}
+	hub.subs[sub] = struct{}{}
+}
+
+func (hub *watchHub) remove(sub *subscription) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	delete(hub.subs, sub)
+	close(sub.ch)
+}
+
+func (hub *watchHub) active() bool {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	return len(hub.subs) > 0
+}
+
+func (hub *watchHub) publish(ev Event) {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	for sub := range hub.subs {
+		if !sub.matcher.match(ev.Triple) {
+			continue
+		}
+
+		sub.send(ev)
+	}
+}
+
+// send delivers the event without blocking. If the subscriber's buffer is
+// full, the event is dropped and counted as missed.
+func (sub *subscription) send(ev Event) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	ev.Missed = sub.missed
+	select {
+	case sub.ch <- ev:
+		sub.missed = 0
+
+	default:
+		sub.missed++
+	}
+}
@@ -0,0 +1,100 @@
+package fabric_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spy16/fabric"
+)
+
+func TestFabric_Watch(suite *testing.T) {
+	suite.Parallel()
+
+	suite.Run("InvalidQuery", func(t *testing.T) {
+		fab := fabric.New(&fabric.InMemoryStore{})
+		_, err := fab.Watch(context.Background(), fabric.Query{Source: fabric.Clause{Type: "unknown", Value: "x"}})
+		if err == nil {
+			t.Errorf("expecting error, got nil")
+		}
+	})
+
+	suite.Run("Events", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fab := fabric.New(&fabric.InMemoryStore{})
+		events, err := fab.Watch(ctx, fabric.Query{Source: fabric.Clause{Type: "eq", Value: "Bob"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		insert(t, fab,
+			fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John"},
+			fabric.Triple{Source: "Alice", Predicate: "knows", Target: "John"},
+		)
+		fab.ReWeight(ctx, fabric.Query{}, 2, true)
+		fab.Upsert(ctx, fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John", Weight: 5}, fabric.KeepExisting)
+		fab.Upsert(ctx, fabric.Triple{Source: "Bob", Predicate: "knows", Target: "John", Weight: 1}, fabric.AddWeight)
+		fab.Delete(ctx, fabric.Query{Predicate: fabric.Clause{Type: "eq", Value: "knows"}})
+		fab.Batch(ctx, func(tx *fabric.Fabric) error {
+			return tx.Insert(ctx, fabric.Triple{Source: "Bob", Predicate: "likes", Target: "Alice"})
+		})
+
+		expected := []string{
+			"insert Bob knows John 0.000000",
+			"reweight Bob knows John 2.000000",
+			"upsert Bob knows John 3.000000",
+			"delete Bob knows John 3.000000",
+			"insert Bob likes Alice 0.000000",
+		}
+		for _, exp := range expected {
+			ev := <-events
+			if got := fmt.Sprintf("%s %s", ev.Type, ev.Triple); got != exp {
+				t.Errorf("expected event '%s', got '%s'", exp, got)
+			}
+		}
+
+		cancel()
+		select {
+		case _, open := <-events:
+			if open {
+				t.Errorf("expecting channel to be closed after cancel")
+			}
+		case <-time.After(time.Second):
+			t.Errorf("channel not closed after cancel")
+		}
+	})
+
+	suite.Run("SlowSubscriber", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fab := fabric.New(&fabric.InMemoryStore{})
+		events, err := fab.Watch(ctx, fabric.Query{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var triples []fabric.Triple
+		for i := 0; i < 200; i++ {
+			triples = append(triples, fabric.Triple{Source: "a", Predicate: "b", Target: fmt.Sprintf("c%d", i)})
+		}
+		if _, err := fab.InsertMany(ctx, triples); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		received := 0
+		for len(events) > 0 {
+			<-events
+			received++
+		}
+
+		insert(t, fab, fabric.Triple{Source: "x", Predicate: "y", Target: "z"})
+		ev := <-events
+		if ev.Missed != 200-received {
+			t.Errorf("expected %d missed events, got %d", 200-received, ev.Missed)
+		}
+	})
+}